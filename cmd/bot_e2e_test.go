@@ -36,7 +36,7 @@ func TestEndToEndBot(t *testing.T) {
 	defer db.Close()
 
 	sqlxDB := sqlx.NewDb(db, "postgres")
-	sourceStorage := storage.NewSourceStorage(sqlxDB)
+	sourceStorage := storage.NewSourceStorage(sqlxDB, nil)
 
 	ctx := context.Background()
 
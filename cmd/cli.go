@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"neuro_scout_bot_v1/internal/config"
+	"neuro_scout_bot_v1/internal/opml"
+	"neuro_scout_bot_v1/internal/storage"
+)
+
+const cliUsage = `usage:
+  neuro_scout_bot                        run the bot
+  neuro_scout_bot import-opml <file>     add the feeds of an OPML file as sources
+  neuro_scout_bot export-opml [file]     write the sources as OPML to a file or stdout`
+
+// runCLI runs a maintenance subcommand instead of the bot.
+func runCLI(args []string) error {
+	switch args[0] {
+	case "import-opml":
+		if len(args) != 2 {
+			return errors.New(cliUsage)
+		}
+		return withSourceStorage(func(ctx context.Context, sources *storage.SourcePostgresStorage) error {
+			return importOPML(ctx, sources, args[1])
+		})
+	case "export-opml":
+		if len(args) > 2 {
+			return errors.New(cliUsage)
+		}
+		return withSourceStorage(func(ctx context.Context, sources *storage.SourcePostgresStorage) error {
+			if len(args) == 1 {
+				return exportOPML(ctx, sources, os.Stdout)
+			}
+
+			file, err := os.Create(args[1])
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			return exportOPML(ctx, sources, file)
+		})
+	default:
+		return errors.New(cliUsage)
+	}
+}
+
+func withSourceStorage(run func(ctx context.Context, sources *storage.SourcePostgresStorage) error) error {
+	db, err := sqlx.Connect("postgres", config.Get().DatabaseDSN)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	secrets, err := secretBox()
+	if err != nil {
+		return fmt.Errorf("invalid secret_key in config: %w", err)
+	}
+
+	return run(ctx, storage.NewSourceStorage(db, secrets))
+}
+
+func importOPML(ctx context.Context, sources opml.SourceStorage, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	feeds, err := opml.Parse(file)
+	if err != nil {
+		return err
+	}
+
+	report, err := opml.Import(ctx, sources, feeds)
+	if err != nil {
+		return err
+	}
+
+	for _, feed := range report.Added {
+		fmt.Printf("added      %s (%s)\n", feed.Name, feed.URL)
+	}
+	for _, feed := range report.Duplicates {
+		fmt.Printf("duplicate  %s (%s)\n", feed.Name, feed.URL)
+	}
+	for feedURL, err := range report.Failed {
+		fmt.Printf("failed     %s: %v\n", feedURL, err)
+	}
+
+	fmt.Printf("\n%d added, %d duplicates, %d failed\n", len(report.Added), len(report.Duplicates), len(report.Failed))
+
+	return nil
+}
+
+func exportOPML(ctx context.Context, sources opml.SourceStorage, w io.Writer) error {
+	feeds, err := opml.Export(ctx, sources)
+	if err != nil {
+		return err
+	}
+
+	return opml.Write(w, "Neuro Scout sources", feeds, time.Now())
+}
@@ -4,18 +4,28 @@ import (
 	"context"
 	"errors"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"neuro_scout_bot_v1/internal/bot"
+	"neuro_scout_bot_v1/internal/bot/middleware"
 	"neuro_scout_bot_v1/internal/botkit"
+	"neuro_scout_bot_v1/internal/canonical"
 	"neuro_scout_bot_v1/internal/config"
+	"neuro_scout_bot_v1/internal/extract"
 	"neuro_scout_bot_v1/internal/fetcher"
+	"neuro_scout_bot_v1/internal/filter"
+	"neuro_scout_bot_v1/internal/fingerprint"
 	"neuro_scout_bot_v1/internal/notifier"
+	"neuro_scout_bot_v1/internal/ratelimit"
+	"neuro_scout_bot_v1/internal/secret"
+	"neuro_scout_bot_v1/internal/source"
 	"neuro_scout_bot_v1/internal/storage"
 	"neuro_scout_bot_v1/internal/summary"
+	"neuro_scout_bot_v1/internal/websub"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/jmoiron/sqlx"
@@ -23,6 +33,26 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := runCLI(os.Args[1:]); err != nil {
+			log.Printf("[ERROR] %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	globalFilter, err := filter.ParseExpr(config.Get().FilterExpression)
+	if err != nil {
+		log.Printf("[ERROR] Invalid filter_expression in config: %v", err)
+		return
+	}
+
+	pipeline, err := fetcher.NewPipeline(config.Get().PipelineStages)
+	if err != nil {
+		log.Printf("[ERROR] Invalid pipeline_stages in config: %v", err)
+		return
+	}
+
 	botAPI, err := tgbotapi.NewBotAPI(config.Get().TelegramBotToken)
 	if err != nil {
 		log.Printf("[ERROR] Failed to create bot API: %v", err)
@@ -52,37 +82,102 @@ func main() {
 	}
 	defer db.Close()
 
+	dedupPolicy := fingerprint.Policy{
+		MaxDistance: config.Get().DuplicateMaxDistance,
+		Lookback:    config.Get().DuplicateLookback,
+	}
+
+	if backfilled, err := storage.NewArticleStorage(db).BackfillArticles(context.Background()); err != nil {
+		log.Printf("[ERROR] Failed to backfill canonical links and fingerprints: %v", err)
+		return
+	} else if backfilled > 0 {
+		log.Printf("[INFO] Backfilled canonical links and fingerprints of %d articles", backfilled)
+	}
+
+	secrets, err := secretBox()
+	if err != nil {
+		log.Printf("[ERROR] Invalid secret_key in config: %v", err)
+		return
+	}
+
+	// Feeds and article pages share the per-host limits.
+	limiter := ratelimit.NewLimiter(config.Get().FetchHostDelay, config.Get().FetchHostBurst)
+
 	var (
-		articleStorage = storage.NewArticleStorage(db)
-		sourceStorage  = storage.NewSourceStorage(db)
-		notifier       = notifier.New(
+		articleStorage    = storage.NewArticleStorage(db)
+		sourceStorage     = storage.NewSourceStorage(db, secrets)
+		filterRuleStorage = storage.NewFilterRuleStorage(db)
+		droppedStorage    = storage.NewDroppedItemStorage(db)
+		sourceKinds       = source.NewDefaultRegistry(limiter)
+		notifier          = notifier.New(
 			articleStorage,
 			summary.NewOpenAISummarizer(config.Get().OpenAIKey, config.Get().OpenAIModel, config.Get().OpenAIPrompt),
 			botAPI,
 			config.Get().NotificationInterval,
 			2*config.Get().FetchInterval,
 			config.Get().TelegramChannelID,
+			dedupPolicy,
 		)
 		fetcher = fetcher.New(
 			articleStorage,
 			sourceStorage,
-			config.Get().FetchInterval,
-			config.Get().FilterKeywords,
+			filterRuleStorage,
+			droppedStorage,
+			canonical.NewResolver(limiter),
+			sourceKinds,
+			fetcher.Config{
+				FetchInterval:    config.Get().FetchInterval,
+				CheckInterval:    config.Get().FetchCheckInterval,
+				PushPollInterval: config.Get().WebSubQuietAfter,
+				Concurrency:      config.Get().FetchConcurrency,
+				Pipeline:         pipeline,
+				FilterKeywords:   config.Get().FilterKeywords,
+				FilterExpr:       globalFilter,
+				Limiter:          limiter,
+				Health: fetcher.HealthPolicy{
+					MaxFailures: config.Get().SourceMaxFailures,
+					StaleAfter:  config.Get().SourceStaleAfter,
+				},
+				Dedup: dedupPolicy,
+			},
 		)
 	)
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	channelPostView := bot.ViewChannelPost(sourceStorage, fetcher)
+	submitMessageView := middleware.AdminsOnlySilent(
+		config.Get().TelegramChannelID,
+		bot.ViewMessageSubmit(sourceStorage, articleStorage, limiter),
+	)
+
 	newsBot := botkit.New(botAPI)
 	newsBot.RegisterCmdView("start", bot.ViewCmdStart)
 	newsBot.RegisterCmdView("listsources", bot.ViewCmdListSource(sourceStorage))
-	newsBot.RegisterCmdView("addsource", bot.ViewCmdAddSource(sourceStorage))
+	newsBot.RegisterCmdView("addsource", bot.ViewCmdAddSource(sourceStorage, sourceKinds))
 	newsBot.RegisterCmdView("getsource", bot.ViewCmdGetSource(sourceStorage))
 	newsBot.RegisterCmdView("deletesource", bot.ViewCmdDeleteSource(sourceStorage))
 	newsBot.RegisterCmdView("setpriority", bot.ViewCmdSetPriority(sourceStorage))
+	newsBot.RegisterCmdView("setinterval", bot.ViewCmdSetInterval(sourceStorage))
+	newsBot.RegisterCmdView("sourcehealth", bot.ViewCmdSourceHealth(sourceStorage, config.Get().SourceStaleAfter))
+	newsBot.RegisterCmdView("enablesource", bot.ViewCmdEnableSource(sourceStorage))
+	newsBot.RegisterCmdView("sethttp", middleware.DeleteMessage(
+		middleware.AdminsOnly(config.Get().TelegramChannelID, bot.ViewCmdSetHTTP(sourceStorage)),
+	))
+	newsBot.RegisterCmdView("linkchannel", bot.ViewCmdLinkChannel(sourceStorage))
+	newsBot.RegisterCmdView("importopml", bot.ViewCmdImportOPML(sourceStorage))
+	newsBot.RegisterCmdView("exportopml", bot.ViewCmdExportOPML(sourceStorage))
+	newsBot.RegisterCmdView("addrule", bot.ViewCmdAddFilterRule(filterRuleStorage))
+	newsBot.RegisterCmdView("listrules", bot.ViewCmdListFilterRules(filterRuleStorage))
+	newsBot.RegisterCmdView("deleterule", bot.ViewCmdDeleteFilterRule(filterRuleStorage))
+	newsBot.RegisterCmdView("testfilter", bot.ViewCmdTestFilter(articleStorage))
+	newsBot.RegisterCmdView("setfilter", bot.ViewCmdSetFilter(sourceStorage))
+	newsBot.RegisterCmdView("duplicates", bot.ViewCmdDuplicates(articleStorage))
+	newsBot.RegisterCmdView("dropped", bot.ViewCmdDropped(droppedStorage, pipeline.Stages()))
 
 	newsBot.RegisterCmdView("findarticles", bot.ViewCmdFindArticles(articleStorage))
+	newsBot.RegisterCmdView("submit", bot.ViewCmdSubmit(sourceStorage, articleStorage, limiter))
 	newsBot.RegisterCmdView("publishtochannel", bot.ViewCmdPublishToChannel(
 		articleStorage,
 		config.Get().TelegramChannelID,
@@ -101,7 +196,22 @@ func main() {
 		{Command: "addsource", Description: "Додати нове джерело"},
 		{Command: "deletesource", Description: "Видалити джерело за ID"},
 		{Command: "setpriority", Description: "Встановити пріоритет джерела"},
+		{Command: "setinterval", Description: "Встановити інтервал оновлення джерела"},
+		{Command: "sourcehealth", Description: "Показати зламані та неактивні джерела"},
+		{Command: "enablesource", Description: "Увімкнути вимкнене джерело за ID"},
+		{Command: "sethttp", Description: "Налаштувати HTTP-запити джерела"},
+		{Command: "linkchannel", Description: "Прив'язати Telegram-канал до джерела"},
+		{Command: "importopml", Description: "Імпортувати джерела з OPML-файлу"},
+		{Command: "exportopml", Description: "Експортувати джерела в OPML-файл"},
+		{Command: "addrule", Description: "Додати правило фільтрації статей"},
+		{Command: "listrules", Description: "Переглянути правила фільтрації"},
+		{Command: "deleterule", Description: "Видалити правило фільтрації за ID"},
+		{Command: "testfilter", Description: "Перевірити правило на останніх статтях"},
+		{Command: "setfilter", Description: "Встановити вираз-фільтр для джерела"},
+		{Command: "duplicates", Description: "Показати відкинуті дублікати статей"},
+		{Command: "dropped", Description: "Показати статті, відкинуті під час обробки"},
 		{Command: "findarticles", Description: "Знайти статті за вказаний період"},
+		{Command: "submit", Description: "Додати статтю вручну за посиланням"},
 		{Command: "publishtochannel", Description: "Опублікувати статті в канал"},
 		{Command: "checkopenai", Description: "Перевірити статус API ключа OpenAI"},
 		{Command: "setopenaikey", Description: "Встановити API ключ OpenAI"},
@@ -133,6 +243,68 @@ func main() {
 		}
 	}(ctx)
 
+	extractor := extract.NewWorker(
+		articleStorage,
+		sourceStorage,
+		extract.NewExtractor(limiter),
+		config.Get().ExtractConcurrency,
+		config.Get().ExtractMaxAttempts,
+	)
+
+	go func(ctx context.Context) {
+		if err := extractor.Start(ctx); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				log.Printf("[ERROR] failed to run text extraction: %v", err)
+				return
+			}
+			log.Printf("[INFO] text extraction stopped")
+		}
+	}(ctx)
+
+	if callbackURL := config.Get().WebSubCallbackURL; callbackURL != "" {
+		subscriber := websub.New(
+			sourceStorage,
+			fetcher,
+			callbackURL,
+			config.Get().WebSubLease,
+			config.Get().WebSubQuietAfter,
+		)
+
+		server := &http.Server{
+			Addr:              config.Get().WebSubListenAddr,
+			Handler:           subscriber.Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		go func() {
+			log.Printf("[INFO] Serving WebSub callbacks on %s", server.Addr)
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("[ERROR] failed to serve WebSub callbacks: %v", err)
+			}
+		}()
+
+		go func(ctx context.Context) {
+			<-ctx.Done()
+
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer shutdownCancel()
+
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				log.Printf("[WARN] failed to stop WebSub server: %v", err)
+			}
+		}(ctx)
+
+		go func(ctx context.Context) {
+			if err := subscriber.Start(ctx); err != nil {
+				if !errors.Is(err, context.Canceled) {
+					log.Printf("[ERROR] failed to run WebSub subscriber: %v", err)
+					return
+				}
+				log.Printf("[INFO] WebSub subscriber stopped")
+			}
+		}(ctx)
+	}
+
 	log.Printf("[INFO] Starting bot...")
 
 	u := tgbotapi.UpdateConfig{
@@ -170,8 +342,29 @@ func main() {
 					u.Offset = update.UpdateID + 1
 					log.Printf("[DEBUG] Processing update ID: %d", update.UpdateID)
 
-					if update.Message != nil && update.Message.IsCommand() {
-						cmd := update.Message.Command()
+					if update.ChannelPost != nil {
+						updateCtx, updateCancel := context.WithTimeout(ctx, time.Minute)
+						if err := channelPostView(updateCtx, botAPI, update); err != nil {
+							log.Printf("[ERROR] Failed to ingest channel post: %v", err)
+						}
+						updateCancel()
+					}
+
+					if update.Message == nil {
+						continue
+					}
+
+					cmd := botkit.MessageCommand(update.Message)
+
+					if cmd == "" && update.Message.Chat.IsPrivate() {
+						updateCtx, updateCancel := context.WithTimeout(ctx, time.Minute)
+						if err := submitMessageView(updateCtx, botAPI, update); err != nil {
+							log.Printf("[ERROR] Failed to submit link from message: %v", err)
+						}
+						updateCancel()
+					}
+
+					if cmd != "" {
 						if cmdView, ok := newsBot.GetCmdView(cmd); ok {
 							updateCtx, updateCancel := context.WithTimeout(ctx, 5*time.Minute)
 							if err := cmdView(updateCtx, botAPI, update); err != nil {
@@ -187,3 +380,14 @@ func main() {
 		}
 	}
 }
+
+// secretBox returns the box that encrypts the secrets of sources, nil when
+// no secret key is configured.
+func secretBox() (*secret.Box, error) {
+	key := config.Get().SecretKey
+	if key == "" {
+		return nil, nil
+	}
+
+	return secret.NewBox(key)
+}
@@ -10,22 +10,13 @@ import (
 
 func AdminsOnly(channelID int64, next botkit.ViewFunc) botkit.ViewFunc {
 	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
-		admins, err := bot.GetChatAdministrators(
-			tgbotapi.ChatAdministratorsConfig{
-				ChatConfig: tgbotapi.ChatConfig{
-					ChatID: channelID,
-				},
-			},
-		)
-
+		admin, err := isAdmin(bot, channelID, update)
 		if err != nil {
 			return err
 		}
 
-		for _, admin := range admins {
-			if admin.User.ID == update.SentFrom().ID {
-				return next(ctx, bot, update)
-			}
+		if admin {
+			return next(ctx, bot, update)
 		}
 
 		if _, err := bot.Send(tgbotapi.NewMessage(
@@ -38,3 +29,38 @@ func AdminsOnly(channelID int64, next botkit.ViewFunc) botkit.ViewFunc {
 		return nil
 	}
 }
+
+// AdminsOnlySilent is AdminsOnly for plain messages: updates from others are
+// ignored without a reply.
+func AdminsOnlySilent(channelID int64, next botkit.ViewFunc) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		admin, err := isAdmin(bot, channelID, update)
+		if err != nil || !admin {
+			return err
+		}
+
+		return next(ctx, bot, update)
+	}
+}
+
+func isAdmin(bot *tgbotapi.BotAPI, channelID int64, update tgbotapi.Update) (bool, error) {
+	admins, err := bot.GetChatAdministrators(
+		tgbotapi.ChatAdministratorsConfig{
+			ChatConfig: tgbotapi.ChatConfig{
+				ChatID: channelID,
+			},
+		},
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, admin := range admins {
+		if admin.User.ID == update.SentFrom().ID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
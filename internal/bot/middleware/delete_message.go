@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"context"
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"neuro_scout_bot_v1/internal/botkit"
+)
+
+// DeleteMessage deletes the command message before anything else runs, for
+// commands that carry secrets, whoever sent them.
+func DeleteMessage(next botkit.ViewFunc) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		if _, err := bot.Request(tgbotapi.NewDeleteMessage(update.Message.Chat.ID, update.Message.MessageID)); err != nil {
+			log.Printf("[WARN] failed to delete command message with secrets: %v", err)
+		}
+
+		return next(ctx, bot, update)
+	}
+}
@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"neuro_scout_bot_v1/internal/botkit"
+	"neuro_scout_bot_v1/internal/model"
+	sourcelib "neuro_scout_bot_v1/internal/source"
+)
+
+type ChannelSourceFinder interface {
+	SourceByChannelID(ctx context.Context, chatID int64) (*model.Source, error)
+}
+
+type ItemIngester interface {
+	Ingest(ctx context.Context, source model.Source, items []model.Item) (int, error)
+}
+
+// ViewChannelPost stores posts of linked channels as articles. Posts of
+// channels that are not linked to a source are ignored.
+func ViewChannelPost(sources ChannelSourceFinder, ingester ItemIngester) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		post := update.ChannelPost
+		if post == nil {
+			return nil
+		}
+
+		source, err := sources.SourceByChannelID(ctx, post.Chat.ID)
+		if err != nil {
+			return fmt.Errorf("failed to find source for channel %d: %w", post.Chat.ID, err)
+		}
+
+		if source == nil || source.Disabled {
+			return nil
+		}
+
+		item, ok := sourcelib.ChannelPostItem(post, source.Name)
+		if !ok {
+			return nil
+		}
+
+		stored, err := ingester.Ingest(ctx, *source, []model.Item{item})
+		if err != nil {
+			return err
+		}
+
+		log.Printf("[INFO] Ingested post %d from channel %q, stored: %d", post.MessageID, source.Name, stored)
+
+		return nil
+	}
+}
@@ -2,23 +2,41 @@ package bot
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"html"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
 
 	"neuro_scout_bot_v1/internal/botkit"
 	"neuro_scout_bot_v1/internal/model"
+	sourcelib "neuro_scout_bot_v1/internal/source"
 )
 
 type SourceStorage interface {
+	Sources(ctx context.Context) ([]model.Source, error)
 	Add(ctx context.Context, source model.Source) (int64, error)
 }
 
-func ViewCmdAddSource(storage SourceStorage) botkit.ViewFunc {
+type SourceFactory interface {
+	Validate(kind string, config json.RawMessage) error
+	Kinds() []string
+	New(m model.Source) (sourcelib.Source, error)
+}
+
+func ViewCmdAddSource(storage SourceStorage, kinds SourceFactory) botkit.ViewFunc {
 	type addSourceArgs struct {
-		Name     string `json:"name"`
-		URL      string `json:"url"`
-		Priority int    `json:"priority"`
+		Name     string          `json:"name"`
+		URL      string          `json:"url"`
+		Priority int             `json:"priority"`
+		Kind     string          `json:"kind"`
+		Config   json.RawMessage `json:"config"`
+		DryRun   bool            `json:"dry_run"`
 	}
 
 	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
@@ -28,8 +46,14 @@ func ViewCmdAddSource(storage SourceStorage) botkit.ViewFunc {
 				"❌ Incorrect command format. Example: <code>/addsource {\"name\":\"Name\",\"url\":\"URL\",\"priority\":5}</code>\n\n"+
 					"Required parameters:\n"+
 					"- <code>name</code> - source name\n"+
-					"- <code>url</code> - RSS/Atom feed URL\n"+
-					"- <code>priority</code> - priority from 1 to 10")
+					"- <code>url</code> - feed URL, or a site page that links to its feed\n"+
+					"- <code>priority</code> - priority from 1 to 10\n\n"+
+					"Optional parameters:\n"+
+					"- <code>kind</code> - source kind, one of: "+strings.Join(kinds.Kinds(), ", ")+" (default: rss)\n"+
+					"- <code>config</code> - kind-specific settings as a JSON object\n"+
+					"- <code>dry_run</code> - only preview the items, do not save the source\n\n"+
+					"HTML example: <code>/addsource {\"name\":\"Lab blog\",\"url\":\"https://lab.example.com/blog\",\"priority\":5,"+
+					"\"kind\":\"html\",\"config\":{\"item\":\"article\",\"title\":\"h2\",\"date\":\"time\",\"teaser\":\"p\"}}</code>")
 			helpMsg.ParseMode = "HTML"
 			if _, err := bot.Send(helpMsg); err != nil {
 				return err
@@ -37,18 +61,77 @@ func ViewCmdAddSource(storage SourceStorage) botkit.ViewFunc {
 			return err
 		}
 
+		if args.Kind == "" {
+			args.Kind = model.SourceKindRSS
+		}
+
 		source := model.Source{
-			Name:     args.Name,
-			FeedURL:  args.URL,
+			Name:     strings.TrimSpace(args.Name),
+			FeedURL:  strings.TrimSpace(args.URL),
 			Priority: int64(args.Priority),
+			Kind:     args.Kind,
+			Config:   args.Config,
 		}
 
-		sourceID, err := storage.Add(ctx, source)
+		rejectMsg := func(format string, a ...any) error {
+			errorMsg := tgbotapi.NewMessage(update.Message.Chat.ID, "❌ "+fmt.Sprintf(format, a...))
+			_, err := bot.Send(errorMsg)
+			return err
+		}
+
+		if err := checkNewSource(source); err != nil {
+			return rejectMsg("Invalid source: %v", err)
+		}
+
+		if err := kinds.Validate(source.Kind, source.Config); err != nil {
+			return rejectMsg("Invalid source: %v\n\nSupported kinds: %s", err, strings.Join(kinds.Kinds(), ", "))
+		}
+
+		var feedTitle string
+		if isFeedKind(source.Kind) {
+			discovery, err := sourcelib.Discover(ctx, source.FeedURL)
+			if err != nil {
+				return rejectMsg("Source check failed: %v", err)
+			}
+
+			source.FeedURL, source.Kind, feedTitle = discovery.FeedURL, discovery.Kind, discovery.Title
+		}
+
+		existing, err := storage.Sources(ctx)
 		if err != nil {
-			// TODO: send error message
 			return err
 		}
 
+		if duplicate, ok := findDuplicateSource(existing, source); ok {
+			return rejectMsg("This feed is already registered as source %d (%s)", duplicate.ID, duplicate.Name)
+		}
+
+		items, err := previewSource(ctx, kinds, source)
+		// Scraped sources depend entirely on their selectors, so finding
+		// nothing means they are wrong.
+		if err == nil && len(items) == 0 && source.Kind == model.SourceKindHTML {
+			err = fmt.Errorf("no items found, check the URL and the selectors")
+		}
+		if err != nil {
+			return rejectMsg("Source check failed: %v", err)
+		}
+
+		previewMsg := tgbotapi.NewMessage(update.Message.Chat.ID, formatPreview(source, feedTitle, items))
+		previewMsg.ParseMode = "HTML"
+		previewMsg.DisableWebPagePreview = true
+		if _, err := bot.Send(previewMsg); err != nil {
+			return err
+		}
+
+		if args.DryRun {
+			return nil
+		}
+
+		sourceID, err := storage.Add(ctx, source)
+		if err != nil {
+			return rejectMsg("Error adding source: %v", err)
+		}
+
 		var (
 			msgText = fmt.Sprintf(
 				"Source added with ID: `%d`\\. Use this ID to update or delete the source\\.",
@@ -66,3 +149,112 @@ func ViewCmdAddSource(storage SourceStorage) botkit.ViewFunc {
 		return nil
 	}
 }
+
+func previewSource(ctx context.Context, kinds SourceFactory, source model.Source) ([]model.Item, error) {
+	src, err := kinds.New(source)
+	if err != nil {
+		return nil, err
+	}
+
+	previewCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	return src.Fetch(previewCtx)
+}
+
+// checkNewSource checks the fields every source needs, before anything is
+// fetched.
+func checkNewSource(source model.Source) error {
+	if source.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if source.Priority < model.MinSourcePriority || source.Priority > model.MaxSourcePriority {
+		return fmt.Errorf("priority must be from %d to %d", model.MinSourcePriority, model.MaxSourcePriority)
+	}
+
+	if source.FeedURL == "" {
+		if isFeedKind(source.Kind) || source.Kind == model.SourceKindHTML {
+			return fmt.Errorf("url is required")
+		}
+		return nil
+	}
+
+	u, err := url.ParseRequestURI(source.FeedURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q", source.FeedURL)
+	}
+
+	return nil
+}
+
+// isFeedKind reports whether the kind reads a feed, which can be found from
+// the site's page.
+func isFeedKind(kind string) bool {
+	return kind == model.SourceKindRSS || kind == model.SourceKindJSONFeed
+}
+
+// findDuplicateSource looks for a source of the same kind with the same URL
+// and config. Sources without a URL, like subreddits, differ by config.
+func findDuplicateSource(sources []model.Source, source model.Source) (model.Source, bool) {
+	normalizedURL := sourcelib.NormalizeFeedURL(source.FeedURL)
+
+	for _, existing := range sources {
+		if sourcelib.NormalizeFeedURL(existing.FeedURL) != normalizedURL {
+			continue
+		}
+
+		if isFeedKind(source.Kind) && isFeedKind(lo.Ternary(existing.Kind == "", model.SourceKindRSS, existing.Kind)) {
+			return existing, true
+		}
+
+		if existing.Kind == source.Kind && sameConfig(existing.Config, source.Config) {
+			return existing, true
+		}
+	}
+
+	return model.Source{}, false
+}
+
+func sameConfig(a, b json.RawMessage) bool {
+	var va, vb any
+	if len(a) == 0 {
+		a = json.RawMessage("{}")
+	}
+	if len(b) == 0 {
+		b = json.RawMessage("{}")
+	}
+
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(va, vb)
+}
+
+func formatPreview(source model.Source, feedTitle string, items []model.Item) string {
+	const maxPreviewItems = 5
+
+	var sb strings.Builder
+	if feedTitle != "" {
+		fmt.Fprintf(&sb, "📰 <b>%s</b> (%s)\n%s\n\n", html.EscapeString(feedTitle), source.Kind, html.EscapeString(source.FeedURL))
+	}
+	fmt.Fprintf(&sb, "🔎 <b>Preview:</b> %d items found\n", len(items))
+
+	for i, item := range items {
+		if i == maxPreviewItems {
+			fmt.Fprintf(&sb, "\n…and %d more", len(items)-maxPreviewItems)
+			break
+		}
+
+		date := "no date"
+		if !item.Date.IsZero() {
+			date = item.Date.Format("2006-01-02")
+		}
+
+		fmt.Fprintf(&sb, "\n%d. <b>%s</b> (%s)\n%s\n",
+			i+1, html.EscapeString(item.Title), date, html.EscapeString(item.Link))
+	}
+
+	return sb.String()
+}
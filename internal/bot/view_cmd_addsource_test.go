@@ -0,0 +1,49 @@
+package bot
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+func TestCheckNewSource(t *testing.T) {
+	valid := model.Source{Name: "Blog", FeedURL: "https://example.com/feed", Priority: 5, Kind: model.SourceKindRSS}
+	assert.NoError(t, checkNewSource(valid))
+
+	for name, modify := range map[string]func(s *model.Source){
+		"priority too low":  func(s *model.Source) { s.Priority = 0 },
+		"priority too high": func(s *model.Source) { s.Priority = 11 },
+		"no name":           func(s *model.Source) { s.Name = "" },
+		"no url":            func(s *model.Source) { s.FeedURL = "" },
+		"relative url":      func(s *model.Source) { s.FeedURL = "example.com/feed" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			source := valid
+			modify(&source)
+			assert.Error(t, checkNewSource(source))
+		})
+	}
+
+	assert.NoError(t, checkNewSource(model.Source{Name: "HN", Priority: 3, Kind: model.SourceKindHN}),
+		"API sources need no url")
+}
+
+func TestFindDuplicateSource(t *testing.T) {
+	existing := []model.Source{
+		{ID: 1, Name: "Blog", FeedURL: "https://example.com/feed/"},
+		{ID: 2, Name: "r/ML", Kind: model.SourceKindReddit, Config: json.RawMessage(`{"subreddit": "MachineLearning"}`)},
+	}
+
+	duplicate, ok := findDuplicateSource(existing, model.Source{FeedURL: "http://example.com/feed", Kind: model.SourceKindJSONFeed})
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), duplicate.ID)
+
+	_, ok = findDuplicateSource(existing, model.Source{Kind: model.SourceKindReddit, Config: json.RawMessage(`{"subreddit":"MachineLearning"}`)})
+	assert.True(t, ok)
+
+	_, ok = findDuplicateSource(existing, model.Source{Kind: model.SourceKindReddit, Config: json.RawMessage(`{"subreddit":"LocalLLaMA"}`)})
+	assert.False(t, ok)
+}
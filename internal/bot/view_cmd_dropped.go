@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"neuro_scout_bot_v1/internal/botkit"
+	"neuro_scout_bot_v1/internal/botkit/markup"
+	"neuro_scout_bot_v1/internal/model"
+)
+
+type DroppedItemLister interface {
+	DroppedItems(ctx context.Context, sourceID int64, stage string, limit uint64) ([]model.DroppedItem, error)
+}
+
+// ViewCmdDropped lists the latest items the pipeline dropped, with the stage
+// and the reason.
+func ViewCmdDropped(lister DroppedItemLister, stages []string) botkit.ViewFunc {
+	type droppedArgs struct {
+		SourceID int64  `json:"source_id"`
+		Stage    string `json:"stage"`
+		Limit    int    `json:"limit"`
+	}
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		var args droppedArgs
+		if raw := strings.TrimSpace(update.Message.CommandArguments()); raw != "" {
+			var err error
+			if args, err = botkit.ParseJSON[droppedArgs](raw); err != nil {
+				helpMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
+					"❌ Incorrect command format. Example: <code>/dropped {\"source_id\":1,\"stage\":\"rules\",\"limit\":20}</code>\n\n"+
+						"All fields are optional. Stages: <code>"+strings.Join(stages, "</code>, <code>")+"</code>\n"+
+						"Limit: from 1 to 50 items")
+				helpMsg.ParseMode = "HTML"
+				if _, err := bot.Send(helpMsg); err != nil {
+					return err
+				}
+				return err
+			}
+		}
+
+		limit := uint64(20)
+		if args.Limit > 0 && args.Limit <= 50 {
+			limit = uint64(args.Limit)
+		}
+
+		items, err := lister.DroppedItems(ctx, args.SourceID, strings.ToLower(strings.TrimSpace(args.Stage)), limit)
+		if err != nil {
+			errorMsg := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf("❌ Error loading dropped items: %v", err))
+			if _, err := bot.Send(errorMsg); err != nil {
+				return err
+			}
+			return err
+		}
+
+		pipeline := "Pipeline: " + markup.EscapeForMarkdown(strings.Join(stages, " → "))
+
+		if len(items) == 0 {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, "ℹ️ No dropped items found\n\n"+pipeline)
+			reply.ParseMode = parseModeMarkdownV2
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+			return nil
+		}
+
+		formatted := make([]string, 0, len(items))
+		for _, item := range items {
+			formatted = append(formatted, formatDroppedItem(item))
+		}
+
+		msgText := fmt.Sprintf(
+			"%s\n\nLatest dropped items \\(%d\\):\n\n%s",
+			pipeline,
+			len(items),
+			strings.Join(formatted, "\n\n"),
+		)
+
+		for _, chunk := range splitLongMessage(msgText, 4000) {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, chunk)
+			reply.ParseMode = parseModeMarkdownV2
+			reply.DisableWebPagePreview = true
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func formatDroppedItem(item model.DroppedItem) string {
+	lines := []string{
+		fmt.Sprintf("🚫 *%s*", markup.EscapeForMarkdown(item.Title)),
+		"🔗 " + markup.EscapeForMarkdown(item.Link),
+		fmt.Sprintf("Stage `%s`: %s", item.Stage, markup.EscapeForMarkdown(item.Reason)),
+		fmt.Sprintf("Source ID: `%d`, last dropped %s", item.SourceID, markup.EscapeForMarkdown(formatHealthTime(item.DroppedAt))),
+	}
+
+	if item.Count > 1 {
+		lines[len(lines)-1] += fmt.Sprintf(", %d times", item.Count)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"neuro_scout_bot_v1/internal/botkit"
+	"neuro_scout_bot_v1/internal/botkit/markup"
+	"neuro_scout_bot_v1/internal/model"
+)
+
+type DuplicateLister interface {
+	RecentDuplicates(ctx context.Context, limit uint64) ([]model.Duplicate, error)
+}
+
+// ViewCmdDuplicates lists the latest items dropped as near-duplicates, with
+// the articles they matched. An optional argument sets how many to show.
+func ViewCmdDuplicates(lister DuplicateLister) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		limit := uint64(20)
+		if arg := strings.TrimSpace(update.Message.CommandArguments()); arg != "" {
+			n, err := strconv.ParseUint(arg, 10, 64)
+			if err != nil || n == 0 || n > 50 {
+				reply := tgbotapi.NewMessage(update.Message.Chat.ID,
+					"❌ Incorrect command format. Example: <code>/duplicates 20</code>\n\nLimit: from 1 to 50 duplicates")
+				reply.ParseMode = "HTML"
+				if _, err := bot.Send(reply); err != nil {
+					return err
+				}
+				return fmt.Errorf("invalid limit %q", arg)
+			}
+			limit = n
+		}
+
+		duplicates, err := lister.RecentDuplicates(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		if len(duplicates) == 0 {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, "ℹ️ No duplicates were found yet")
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+			return nil
+		}
+
+		formatted := make([]string, 0, len(duplicates))
+		for _, duplicate := range duplicates {
+			formatted = append(formatted, formatDuplicate(duplicate))
+		}
+
+		msgText := fmt.Sprintf(
+			"Latest duplicates \\(%d\\):\n\n%s",
+			len(duplicates),
+			strings.Join(formatted, "\n\n"),
+		)
+
+		for _, chunk := range splitLongMessage(msgText, 4000) {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, chunk)
+			reply.ParseMode = parseModeMarkdownV2
+			reply.DisableWebPagePreview = true
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func formatDuplicate(duplicate model.Duplicate) string {
+	return strings.Join([]string{
+		fmt.Sprintf("🔁 *%s*", markup.EscapeForMarkdown(duplicate.Title)),
+		"🔗 " + markup.EscapeForMarkdown(duplicate.Link),
+		fmt.Sprintf(
+			"Matched article `%d` at distance %d: %s",
+			duplicate.Match.ArticleID,
+			duplicate.Match.Distance,
+			markup.EscapeForMarkdown(duplicate.Match.Title),
+		),
+		"🔗 " + markup.EscapeForMarkdown(duplicate.Match.Link),
+		fmt.Sprintf("Source ID: `%d`, detected %s", duplicate.SourceID, markup.EscapeForMarkdown(formatHealthTime(duplicate.DetectedAt))),
+	}, "\n")
+}
@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"neuro_scout_bot_v1/internal/botkit"
+)
+
+type SourceEnabler interface {
+	Enable(ctx context.Context, sourceID int64) error
+}
+
+func ViewCmdEnableSource(enabler SourceEnabler) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		idStr := strings.TrimSpace(update.Message.CommandArguments())
+
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			helpMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
+				"❌ Incorrect command format. Example: <code>/enablesource 1</code>")
+			helpMsg.ParseMode = "HTML"
+			if _, err := bot.Send(helpMsg); err != nil {
+				return err
+			}
+			return err
+		}
+
+		if err := enabler.Enable(ctx, id); err != nil {
+			errorMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
+				fmt.Sprintf("❌ Error enabling source: %v", err))
+			if _, err := bot.Send(errorMsg); err != nil {
+				return err
+			}
+			return err
+		}
+
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+			fmt.Sprintf("✅ Source %d is enabled and will be fetched on the next check", id))
+		if _, err := bot.Send(msg); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
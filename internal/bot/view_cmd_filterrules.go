@@ -0,0 +1,258 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"neuro_scout_bot_v1/internal/botkit"
+	"neuro_scout_bot_v1/internal/filter"
+	"neuro_scout_bot_v1/internal/model"
+)
+
+type FilterRuleStorage interface {
+	FilterRules(ctx context.Context) ([]model.FilterRule, error)
+	AddFilterRule(ctx context.Context, rule model.FilterRule) (int64, error)
+	DeleteFilterRule(ctx context.Context, id int64) error
+}
+
+type filterRuleArgs struct {
+	SourceID int64  `json:"source_id"`
+	Action   string `json:"action"`
+	Match    string `json:"match"`
+	Pattern  string `json:"pattern"`
+}
+
+func (a filterRuleArgs) rule() model.FilterRule {
+	return model.FilterRule{
+		SourceID: a.SourceID,
+		Action:   strings.ToLower(a.Action),
+		Match:    strings.ToLower(a.Match),
+		Pattern:  strings.TrimSpace(a.Pattern),
+	}
+}
+
+const filterRuleHelp = "Rule fields:\n" +
+	"- <code>source_id</code> - source the rule applies to, omit for all sources\n" +
+	"- <code>action</code> - <code>include</code> or <code>exclude</code>\n" +
+	"- <code>match</code> - <code>keyword</code>, <code>regex</code>, <code>category</code> or <code>author</code>\n" +
+	"- <code>pattern</code> - what to match\n\n" +
+	"Keywords and regexes are looked for in the title, categories and summary, ignoring case. " +
+	"If a source has include rules, only items matching one of them are stored."
+
+func ViewCmdAddFilterRule(storage FilterRuleStorage) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[filterRuleArgs](update.Message.CommandArguments())
+		if err != nil {
+			helpMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
+				"❌ Incorrect command format. Example: <code>/addrule {\"action\":\"exclude\",\"match\":\"keyword\",\"pattern\":\"crypto\"}</code>\n\n"+
+					filterRuleHelp)
+			helpMsg.ParseMode = "HTML"
+			if _, err := bot.Send(helpMsg); err != nil {
+				return err
+			}
+			return err
+		}
+
+		rule := args.rule()
+		if _, err := filter.NewMatcher(rule); err != nil {
+			errorMsg := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf("❌ Invalid rule: %v", err))
+			if _, err := bot.Send(errorMsg); err != nil {
+				return err
+			}
+			return nil
+		}
+
+		id, err := storage.AddFilterRule(ctx, rule)
+		if err != nil {
+			errorMsg := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf("❌ Error adding rule: %v", err))
+			if _, err := bot.Send(errorMsg); err != nil {
+				return err
+			}
+			return err
+		}
+
+		rule.ID = id
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+			fmt.Sprintf("✅ Rule added: %s\n\nIt applies to items fetched from now on.", formatFilterRule(rule)))
+		if _, err := bot.Send(msg); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func ViewCmdListFilterRules(storage FilterRuleStorage) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		rules, err := storage.FilterRules(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(rules) == 0 {
+			_, err := bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "ℹ️ There are no filter rules"))
+			return err
+		}
+
+		lines := make([]string, 0, len(rules))
+		for _, rule := range rules {
+			lines = append(lines, formatFilterRule(rule))
+		}
+
+		msgText := fmt.Sprintf("Filter rules (total %d):\n\n%s", len(rules), strings.Join(lines, "\n"))
+		for _, chunk := range splitLongMessage(msgText, 4000) {
+			if _, err := bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, chunk)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func ViewCmdDeleteFilterRule(storage FilterRuleStorage) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		id, err := strconv.ParseInt(strings.TrimSpace(update.Message.CommandArguments()), 10, 64)
+		if err != nil {
+			helpMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
+				"❌ Incorrect command format. Example: <code>/deleterule 3</code>")
+			helpMsg.ParseMode = "HTML"
+			if _, err := bot.Send(helpMsg); err != nil {
+				return err
+			}
+			return err
+		}
+
+		if err := storage.DeleteFilterRule(ctx, id); err != nil {
+			errorMsg := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf("❌ Error deleting rule: %v", err))
+			if _, err := bot.Send(errorMsg); err != nil {
+				return err
+			}
+			return nil
+		}
+
+		_, err = bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf("✅ Rule %d deleted", id)))
+		return err
+	}
+}
+
+type SourceArticleFinder interface {
+	FindSourceArticlesByTimePeriod(ctx context.Context, since time.Time, limit uint64, sourceID int64) ([]model.Article, error)
+}
+
+// ViewCmdTestFilter shows which recent articles a rule would have matched,
+// without saving the rule.
+func ViewCmdTestFilter(finder SourceArticleFinder) botkit.ViewFunc {
+	type testFilterArgs struct {
+		filterRuleArgs
+		Days int `json:"days"`
+	}
+
+	const (
+		defaultTestDays  = 7
+		maxTestArticles  = 500
+		maxShownArticles = 20
+	)
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[testFilterArgs](update.Message.CommandArguments())
+
+		var matcher *filter.Matcher
+		if err == nil {
+			matcher, err = filter.NewMatcher(args.rule())
+		}
+
+		if err != nil {
+			helpMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
+				"❌ Incorrect command format. Example: <code>/testfilter {\"action\":\"include\",\"match\":\"regex\",\"pattern\":\"\\\\bLLMs?\\\\b\",\"days\":3}</code>\n\n"+
+					filterRuleHelp+"\n\n<code>days</code> - how far back to look, 7 by default")
+			helpMsg.ParseMode = "HTML"
+			if _, err := bot.Send(helpMsg); err != nil {
+				return err
+			}
+			return err
+		}
+
+		days := args.Days
+		if days <= 0 {
+			days = defaultTestDays
+		}
+
+		articles, err := finder.FindSourceArticlesByTimePeriod(ctx, time.Now().AddDate(0, 0, -days), maxTestArticles, args.SourceID)
+		if err != nil {
+			return err
+		}
+
+		var matched []model.Article
+		for _, article := range articles {
+			if matcher.Match(articleItem(article)) {
+				matched = append(matched, article)
+			}
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "🧪 %s\n\nMatched %d of %d articles from the last %d days.",
+			formatFilterRule(matcher.Rule), len(matched), len(articles), days)
+
+		if matcher.Rule.Action == model.FilterActionExclude {
+			sb.WriteString(" Matched articles would have been dropped.")
+		} else {
+			sb.WriteString(" Articles that match no include rule would have been dropped.")
+		}
+
+		for i, article := range matched {
+			if i == maxShownArticles {
+				fmt.Fprintf(&sb, "\n\n…and %d more", len(matched)-maxShownArticles)
+				break
+			}
+			fmt.Fprintf(&sb, "\n\n• %s\n%s", article.Title, article.Link)
+		}
+
+		for _, chunk := range splitLongMessage(sb.String(), 4000) {
+			msg := tgbotapi.NewMessage(update.Message.Chat.ID, chunk)
+			msg.DisableWebPagePreview = true
+			if _, err := bot.Send(msg); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// articleItem rebuilds the item fields filter rules look at from a stored
+// article.
+func articleItem(article model.Article) model.Item {
+	return model.Item{
+		Title:           article.Title,
+		Categories:      article.Categories,
+		PrimaryCategory: article.PrimaryCategory,
+		Authors:         article.Authors,
+		Link:            article.Link,
+		GUID:            article.GUID,
+		ImageURL:        article.ImageURL,
+		Date:            article.PublishedAt,
+		Updated:         article.UpdatedAt,
+		Summary:         article.Summary,
+		Content:         article.Content,
+	}
+}
+
+func formatFilterRule(rule model.FilterRule) string {
+	scope := "all sources"
+	if rule.SourceID != 0 {
+		scope = fmt.Sprintf("source %d", rule.SourceID)
+	}
+
+	text := fmt.Sprintf("%s %s %q (%s)", rule.Action, rule.Match, rule.Pattern, scope)
+	if rule.ID != 0 {
+		text = fmt.Sprintf("#%d %s", rule.ID, text)
+	}
+
+	return text
+}
@@ -14,13 +14,14 @@ import (
 )
 
 type ArticleFinder interface {
-	FindArticlesByTimePeriod(ctx context.Context, since time.Time, limit uint64) ([]model.Article, error)
+	FindArticlesByTimePeriod(ctx context.Context, since time.Time, limit uint64, tag string) ([]model.Article, error)
 }
 
 func ViewCmdFindArticles(finder ArticleFinder) botkit.ViewFunc {
 	type findArticlesArgs struct {
 		Period string `json:"period"` // "day", "week", "month"
 		Limit  int    `json:"limit"`
+		Tag    string `json:"tag"`
 	}
 
 	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
@@ -30,7 +31,8 @@ func ViewCmdFindArticles(finder ArticleFinder) botkit.ViewFunc {
 			helpMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
 				"❌ Incorrect command format. Example: <code>/findarticles {\"period\":\"week\",\"limit\":10}</code>\n\n"+
 					"Supported periods: <code>day</code>, <code>week</code>, <code>month</code>\n"+
-					"Limit: from 1 to 50 articles")
+					"Limit: from 1 to 50 articles\n"+
+					"Tag (optional): only articles with this category, e.g. <code>\"tag\":\"cs.LG\"</code>")
 			helpMsg.ParseMode = "HTML"
 			if _, err := bot.Send(helpMsg); err != nil {
 				return err
@@ -70,7 +72,7 @@ func ViewCmdFindArticles(finder ArticleFinder) botkit.ViewFunc {
 			since = now.AddDate(0, 0, -7)
 		}
 
-		articles, err := finder.FindArticlesByTimePeriod(ctx, since, limit)
+		articles, err := finder.FindArticlesByTimePeriod(ctx, since, limit, args.Tag)
 		if err != nil {
 
 			errorMsg := tgbotapi.NewEditMessageText(
@@ -110,6 +112,9 @@ func ViewCmdFindArticles(finder ArticleFinder) botkit.ViewFunc {
 				markup.EscapeForMarkdown(article.Title),
 				markup.EscapeForMarkdown(pubDate),
 				markup.EscapeForMarkdown(article.Link))
+			if len(article.Tags) > 0 {
+				articleStr += "\n🏷 " + markup.EscapeForMarkdown(strings.Join(article.Tags, ", "))
+			}
 			articlesFormatted = append(articlesFormatted, articleStr)
 		}
 
@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
 
 	"neuro_scout_bot_v1/internal/botkit"
 	"neuro_scout_bot_v1/internal/botkit/markup"
@@ -42,11 +45,50 @@ func ViewCmdGetSource(provider SourceProvider) botkit.ViewFunc {
 }
 
 func formatSource(source model.Source) string {
-	return fmt.Sprintf(
-		"🌐 *%s*\nID: `%d`\nURL feed: %s\nPriority: %d",
-		markup.EscapeForMarkdown(source.Name),
+	interval := "auto"
+	if source.FetchInterval > 0 {
+		interval = source.FetchInterval.String()
+	}
+
+	nextFetch := "now"
+	if !source.NextFetchAt.IsZero() {
+		nextFetch = source.NextFetchAt.Format("2006-01-02 15:04")
+	}
+
+	name := markup.EscapeForMarkdown(source.Name)
+	if source.Disabled {
+		name += " \\(disabled\\)"
+	}
+	if source.Group != "" {
+		name += " · " + markup.EscapeForMarkdown(source.Group)
+	}
+
+	text := fmt.Sprintf(
+		"🌐 *%s*\nID: `%d`\nKind: %s\nURL feed: %s\nPriority: %d\nFetch interval: %s\nNext fetch: %s",
+		name,
 		source.ID,
+		markup.EscapeForMarkdown(lo.Ternary(source.Kind == "", model.SourceKindRSS, source.Kind)),
 		markup.EscapeForMarkdown(source.FeedURL),
 		source.Priority,
+		markup.EscapeForMarkdown(interval),
+		markup.EscapeForMarkdown(nextFetch),
 	)
+
+	if source.Filter != "" {
+		text += "\nFilter: " + markup.EscapeForMarkdown(source.Filter)
+	}
+
+	if source.WebSub.Hub != "" {
+		state := lo.Ternary(source.WebSub.State == "", "not subscribed", source.WebSub.State)
+		if source.WebSub.Active(time.Now()) {
+			state += ", lease until " + source.WebSub.LeaseExpiresAt.Format("2006-01-02 15:04")
+		}
+		text += "\nWebSub: " + markup.EscapeForMarkdown(source.WebSub.Hub+" ("+state+")")
+	}
+
+	if !source.HTTP.IsZero() {
+		text += "\nHTTP: " + markup.EscapeForMarkdown(strings.Join(describeHTTPOptions(source.HTTP), "; "))
+	}
+
+	return text
 }
@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"neuro_scout_bot_v1/internal/botkit"
+)
+
+type ChannelLinker interface {
+	LinkChannel(ctx context.Context, sourceID int64, chatID int64) error
+}
+
+func ViewCmdLinkChannel(linker ChannelLinker) botkit.ViewFunc {
+	type linkChannelArgs struct {
+		SourceID  int64 `json:"source_id"`
+		ChannelID int64 `json:"channel_id"`
+	}
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[linkChannelArgs](update.Message.CommandArguments())
+		if err != nil || args.SourceID == 0 {
+			helpMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
+				"❌ Incorrect command format. Example: <code>/linkchannel {\"source_id\":1,\"channel_id\":-1001234567890}</code>\n\n"+
+					"The bot must be an admin of the channel to receive its posts.\n"+
+					"Use <code>\"channel_id\":0</code> to unlink the channel.")
+			helpMsg.ParseMode = "HTML"
+			if _, err := bot.Send(helpMsg); err != nil {
+				return err
+			}
+			return err
+		}
+
+		if args.ChannelID != 0 {
+			if err := checkChannelAdmin(bot, args.ChannelID); err != nil {
+				errorMsg := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf("❌ %v", err))
+				if _, err := bot.Send(errorMsg); err != nil {
+					return err
+				}
+				return nil
+			}
+		}
+
+		if err := linker.LinkChannel(ctx, args.SourceID, args.ChannelID); err != nil {
+			errorMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
+				fmt.Sprintf("❌ Error linking channel: %v", err))
+			if _, err := bot.Send(errorMsg); err != nil {
+				return err
+			}
+			return err
+		}
+
+		text := fmt.Sprintf("✅ Channel %d is linked to source %d. New posts will be stored as they are published.", args.ChannelID, args.SourceID)
+		if args.ChannelID == 0 {
+			text = fmt.Sprintf("✅ Source %d is unlinked from its channel and will be fetched as usual.", args.SourceID)
+		}
+
+		if _, err := bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, text)); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// checkChannelAdmin makes sure the bot receives the channel's posts, which
+// Telegram only sends to channel admins.
+func checkChannelAdmin(bot *tgbotapi.BotAPI, channelID int64) error {
+	member, err := bot.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{
+			ChatID: channelID,
+			UserID: bot.Self.ID,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot access channel %d: %w", channelID, err)
+	}
+
+	if !member.IsAdministrator() && !member.IsCreator() {
+		return fmt.Errorf("the bot is not an admin of channel %d", channelID)
+	}
+
+	return nil
+}
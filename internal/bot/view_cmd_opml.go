@@ -0,0 +1,144 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"neuro_scout_bot_v1/internal/botkit"
+	"neuro_scout_bot_v1/internal/opml"
+)
+
+// maxOPMLSize keeps a wrong upload from being read into memory whole.
+const maxOPMLSize = 5 << 20
+
+// ViewCmdImportOPML adds the feeds of an OPML file sent with the command as
+// its caption, or replied to with the command.
+func ViewCmdImportOPML(storage opml.SourceStorage) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		document := update.Message.Document
+		if document == nil && update.Message.ReplyToMessage != nil {
+			document = update.Message.ReplyToMessage.Document
+		}
+
+		if document == nil {
+			helpMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
+				"❌ Send an OPML file with the caption <code>/importopml</code>, or reply to the file with <code>/importopml</code>.")
+			helpMsg.ParseMode = "HTML"
+			_, err := bot.Send(helpMsg)
+			return err
+		}
+
+		feeds, err := downloadOPML(ctx, bot, document)
+		if err != nil {
+			errorMsg := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf("❌ Error reading OPML file: %v", err))
+			if _, err := bot.Send(errorMsg); err != nil {
+				return err
+			}
+			return nil
+		}
+
+		report, err := opml.Import(ctx, storage, feeds)
+		if err != nil {
+			errorMsg := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf("❌ Error importing sources: %v", err))
+			if _, err := bot.Send(errorMsg); err != nil {
+				return err
+			}
+			return err
+		}
+
+		for _, chunk := range splitLongMessage(formatImportReport(report), 4000) {
+			if _, err := bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, chunk)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func downloadOPML(ctx context.Context, bot *tgbotapi.BotAPI, document *tgbotapi.Document) ([]opml.Feed, error) {
+	if document.FileSize > maxOPMLSize {
+		return nil, fmt.Errorf("file is too large (%d bytes)", document.FileSize)
+	}
+
+	fileURL, err := bot.GetFileDirectURL(document.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		// The error holds the URL, which contains the bot token.
+		return nil, fmt.Errorf("failed to download file")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: %s", resp.Status)
+	}
+
+	return opml.Parse(io.LimitReader(resp.Body, maxOPMLSize))
+}
+
+func formatImportReport(report opml.ImportReport) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "📥 OPML import finished\n\nAdded: %d\nSkipped as duplicates: %d\nFailed: %d\n",
+		len(report.Added), len(report.Duplicates), len(report.Failed))
+
+	if len(report.Duplicates) > 0 {
+		sb.WriteString("\nDuplicates:\n")
+		for _, feed := range report.Duplicates {
+			fmt.Fprintf(&sb, "• %s — %s\n", feed.Name, feed.URL)
+		}
+	}
+
+	if len(report.Failed) > 0 {
+		sb.WriteString("\nFailed:\n")
+		for feedURL, err := range report.Failed {
+			fmt.Fprintf(&sb, "• %s: %v\n", feedURL, err)
+		}
+	}
+
+	return sb.String()
+}
+
+// ViewCmdExportOPML sends the sources back as an OPML file.
+func ViewCmdExportOPML(storage opml.SourceStorage) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		feeds, err := opml.Export(ctx, storage)
+		if err != nil {
+			return err
+		}
+
+		var buf bytes.Buffer
+		if err := opml.Write(&buf, "Neuro Scout sources", feeds, time.Now()); err != nil {
+			return err
+		}
+
+		doc := tgbotapi.NewDocument(update.Message.Chat.ID, tgbotapi.FileBytes{
+			Name:  "sources.opml",
+			Bytes: buf.Bytes(),
+		})
+		doc.Caption = fmt.Sprintf("📤 %d sources", len(feeds))
+
+		if _, err := bot.Send(doc); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
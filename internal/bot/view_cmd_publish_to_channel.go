@@ -3,23 +3,20 @@ package bot
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
-	"regexp"
 	"strings"
 	"time"
 
-	"github.com/go-shiori/go-readability"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"neuro_scout_bot_v1/internal/botkit"
 	"neuro_scout_bot_v1/internal/botkit/markup"
+	"neuro_scout_bot_v1/internal/extract"
 	"neuro_scout_bot_v1/internal/model"
 )
 
 type ArticlePublisher interface {
-	FindArticlesByTimePeriod(ctx context.Context, since time.Time, limit uint64) ([]model.Article, error)
+	FindArticlesByTimePeriod(ctx context.Context, since time.Time, limit uint64, tag string) ([]model.Article, error)
 	MarkAsPosted(ctx context.Context, article model.Article) error
 }
 
@@ -27,61 +24,15 @@ type Summarizer interface {
 	Summarize(text string) (string, error)
 }
 
-var redundantNewLines = regexp.MustCompile(`\n{3,}`)
-
-func cleanupText(text string) string {
-	return redundantNewLines.ReplaceAllString(text, "\n")
-}
-
 func extractSummary(summarizer Summarizer, article model.Article) (string, error) {
-	var r io.Reader
-
-	if article.Summary != "" {
-		log.Printf("[INFO] Using existing article summary for: %s", article.Title)
-		r = strings.NewReader(article.Summary)
-	} else {
-		log.Printf("[INFO] Fetching content from URL: %s", article.Link)
-		client := &http.Client{
-			Timeout: 30 * time.Second,
-		}
-
-		req, err := http.NewRequest("GET", article.Link, nil)
-		if err != nil {
-			log.Printf("[ERROR] Failed to create request: %v", err)
-			return "", err
-		}
-
-		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-
-		resp, err := client.Do(req)
-		if err != nil {
-			log.Printf("[ERROR] Failed to fetch article from %s: %v", article.Link, err)
-			return "", err
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			log.Printf("[ERROR] Bad response status: %s for URL: %s", resp.Status, article.Link)
-			return "", fmt.Errorf("bad response status: %s", resp.Status)
-		}
-
-		r = resp.Body
-	}
-
-	log.Printf("[INFO] Parsing article with readability")
-	doc, err := readability.FromReader(r, nil)
+	text, err := extract.ArticleText(article)
 	if err != nil {
-		log.Printf("[ERROR] Failed to parse article with readability: %v", err)
+		log.Printf("[WARN] Article %q has no stored text to summarize: %v", article.Title, err)
 		return "", err
 	}
 
-	text := cleanupText(doc.TextContent)
-
 	if len(text) < 100 {
 		log.Printf("[WARN] Article text is too short (%d chars), may not generate good summary", len(text))
-		if len(text) < 20 {
-			return "", fmt.Errorf("article text is too short to summarize")
-		}
 	}
 
 	preview := text
@@ -110,6 +61,7 @@ func ViewCmdPublishToChannel(publisher ArticlePublisher, channelID int64, summar
 	type publishArgs struct {
 		Period string `json:"period"`
 		Limit  int    `json:"limit"`
+		Tag    string `json:"tag"`
 	}
 
 	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
@@ -118,7 +70,8 @@ func ViewCmdPublishToChannel(publisher ArticlePublisher, channelID int64, summar
 			helpMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
 				"❌ Incorrect command format. Example: <code>/publishtochannel {\"period\":\"week\",\"limit\":5}</code>\n\n"+
 					"Supported periods: <code>day</code>, <code>week</code>, <code>month</code>\n"+
-					"Limit: from 1 to 20 articles")
+					"Limit: from 1 to 20 articles\n"+
+					"Tag (optional): only articles with this category")
 			helpMsg.ParseMode = "HTML"
 			if _, err := bot.Send(helpMsg); err != nil {
 				return err
@@ -157,7 +110,7 @@ func ViewCmdPublishToChannel(publisher ArticlePublisher, channelID int64, summar
 			since = now.AddDate(0, 0, -7)
 		}
 
-		articles, err := publisher.FindArticlesByTimePeriod(ctx, since, limit)
+		articles, err := publisher.FindArticlesByTimePeriod(ctx, since, limit, args.Tag)
 		if err != nil {
 			errorMsg := tgbotapi.NewEditMessageText(
 				update.Message.Chat.ID,
@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"neuro_scout_bot_v1/internal/botkit"
+	"neuro_scout_bot_v1/internal/filter"
+)
+
+type FilterSetter interface {
+	SetFilter(ctx context.Context, sourceID int64, expr string) error
+}
+
+// ViewCmdSetFilter sets the filter expression of a source:
+// /setfilter <source id> <expression>. The expression may span several
+// lines; leaving it out removes the filter.
+func ViewCmdSetFilter(setter FilterSetter) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		idArg, exprArg := splitFirstField(update.Message.CommandArguments())
+
+		sourceID, err := strconv.ParseInt(idArg, 10, 64)
+		if err != nil {
+			helpMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
+				"❌ Incorrect command format. Example: <code>/setfilter 1 \"LLM\" AND NOT title contains \"crypto\" AND age &lt; 7d</code>\n\n"+
+					"Leave the expression out to remove the filter. Only items that match the filter are stored.")
+			helpMsg.ParseMode = "HTML"
+			if _, err := bot.Send(helpMsg); err != nil {
+				return err
+			}
+			return err
+		}
+
+		if _, err := filter.ParseExpr(exprArg); err != nil {
+			errorMsg := tgbotapi.NewMessage(update.Message.Chat.ID, formatExprError(exprArg, err))
+			errorMsg.ParseMode = "HTML"
+			if _, err := bot.Send(errorMsg); err != nil {
+				return err
+			}
+			return nil
+		}
+
+		if err := setter.SetFilter(ctx, sourceID, exprArg); err != nil {
+			errorMsg := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf("❌ Error setting filter: %v", err))
+			if _, err := bot.Send(errorMsg); err != nil {
+				return err
+			}
+			return err
+		}
+
+		text := fmt.Sprintf("✅ Filter of source %d removed.", sourceID)
+		if exprArg != "" {
+			text = fmt.Sprintf("✅ Filter of source %d set. It applies to items fetched from now on.", sourceID)
+		}
+
+		if _, err := bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, text)); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// splitFirstField splits off the first whitespace-separated field and returns
+// it with the trimmed rest.
+func splitFirstField(args string) (string, string) {
+	args = strings.TrimSpace(args)
+
+	i := strings.IndexFunc(args, unicode.IsSpace)
+	if i < 0 {
+		return args, ""
+	}
+
+	return args[:i], strings.TrimSpace(args[i:])
+}
+
+// formatExprError shows a parse error as HTML with the offending line and a
+// caret under the column.
+func formatExprError(expr string, err error) string {
+	var syntaxErr *filter.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return "❌ Invalid filter: " + html.EscapeString(err.Error())
+	}
+
+	lines := strings.Split(expr, "\n")
+	line := ""
+	if syntaxErr.Line <= len(lines) {
+		line = lines[syntaxErr.Line-1]
+	}
+
+	caret := strings.Repeat(" ", syntaxErr.Column-1) + "^"
+
+	return fmt.Sprintf("❌ Invalid filter at line %d, column %d: %s\n\n<pre>%s\n%s</pre>",
+		syntaxErr.Line, syntaxErr.Column, html.EscapeString(syntaxErr.Msg),
+		html.EscapeString(line), caret)
+}
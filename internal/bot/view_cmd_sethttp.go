@@ -0,0 +1,160 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
+
+	"neuro_scout_bot_v1/internal/botkit"
+	"neuro_scout_bot_v1/internal/httpclient"
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/secret"
+)
+
+type HTTPOptionsSetter interface {
+	SourceByID(ctx context.Context, id int64) (*model.Source, error)
+	SetHTTPOptions(ctx context.Context, sourceID int64, opts model.HTTPOptions) error
+}
+
+// ViewCmdSetHTTP replaces the HTTP options of a source. Options left out are
+// reset. The message carries secrets, so register it behind DeleteMessage.
+func ViewCmdSetHTTP(setter HTTPOptionsSetter) botkit.ViewFunc {
+	type basicAuthArgs struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	type setHTTPArgs struct {
+		SourceID           int64             `json:"source_id"`
+		UserAgent          string            `json:"user_agent"`
+		Headers            map[string]string `json:"headers"`
+		BasicAuth          *basicAuthArgs    `json:"basic_auth"`
+		BearerToken        string            `json:"bearer_token"`
+		Cookies            map[string]string `json:"cookies"`
+		ProxyURL           string            `json:"proxy_url"`
+		InsecureSkipVerify bool              `json:"insecure_skip_verify"`
+		Timeout            string            `json:"timeout"`
+	}
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[setHTTPArgs](update.Message.CommandArguments())
+
+		opts := model.HTTPOptions{
+			UserAgent:          strings.TrimSpace(args.UserAgent),
+			Headers:            args.Headers,
+			BearerToken:        args.BearerToken,
+			Cookies:            args.Cookies,
+			ProxyURL:           strings.TrimSpace(args.ProxyURL),
+			InsecureSkipVerify: args.InsecureSkipVerify,
+		}
+		if args.BasicAuth != nil {
+			opts.Username, opts.Password = args.BasicAuth.Username, args.BasicAuth.Password
+		}
+		if err == nil {
+			opts.Timeout, err = parseFetchInterval(args.Timeout)
+		}
+
+		if err != nil || args.SourceID == 0 {
+			helpMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
+				"❌ Incorrect command format. Example: <code>/sethttp {\"source_id\":1,\"user_agent\":\"NeuroScout/1.0\",\"bearer_token\":\"TOKEN\",\"timeout\":\"45s\"}</code>\n\n"+
+					"Optional parameters:\n"+
+					"- <code>user_agent</code> - User-Agent header\n"+
+					"- <code>headers</code> - extra headers as a JSON object\n"+
+					"- <code>basic_auth</code> - <code>{\"username\":\"…\",\"password\":\"…\"}</code>\n"+
+					"- <code>bearer_token</code> - token for the Authorization header\n"+
+					"- <code>cookies</code> - cookies as a JSON object\n"+
+					"- <code>proxy_url</code> - http, https or socks5 proxy\n"+
+					"- <code>insecure_skip_verify</code> - skip TLS certificate checks, for internal hosts\n"+
+					"- <code>timeout</code> - request timeout, such as <code>45s</code>\n\n"+
+					"The options replace the current ones; send only the source ID to reset them. "+
+					"Headers, credentials, cookies and the proxy are stored encrypted.")
+			helpMsg.ParseMode = "HTML"
+			if _, err := bot.Send(helpMsg); err != nil {
+				return err
+			}
+			return lo.Ternary(err != nil, err, errors.New("source_id is required"))
+		}
+
+		rejectMsg := func(format string, a ...any) error {
+			errorMsg := tgbotapi.NewMessage(update.Message.Chat.ID, "❌ "+fmt.Sprintf(format, a...))
+			_, err := bot.Send(errorMsg)
+			return err
+		}
+
+		if err := httpclient.Validate(opts); err != nil {
+			return rejectMsg("Invalid HTTP options: %v", err)
+		}
+
+		source, err := setter.SourceByID(ctx, args.SourceID)
+		if err != nil || source == nil {
+			return rejectMsg("Source %d not found", args.SourceID)
+		}
+
+		if err := setter.SetHTTPOptions(ctx, source.ID, opts); err != nil {
+			if errors.Is(err, secret.ErrNoKey) {
+				return rejectMsg("Secrets cannot be stored: set secret_key in the config first")
+			}
+			if sendErr := rejectMsg("Error setting HTTP options: %v", err); sendErr != nil {
+				return sendErr
+			}
+			return err
+		}
+
+		text := fmt.Sprintf("✅ HTTP options of source %q reset to the defaults.", source.Name)
+		if !opts.IsZero() {
+			text = fmt.Sprintf("✅ HTTP options of source %q set. They apply from the next fetch.\n\n%s",
+				source.Name, strings.Join(describeHTTPOptions(opts), "\n"))
+		}
+
+		if _, err := bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, text)); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// describeHTTPOptions lists the options that are set, without the values of
+// secrets.
+func describeHTTPOptions(opts model.HTTPOptions) []string {
+	var lines []string
+
+	if opts.UserAgent != "" {
+		lines = append(lines, "User agent: "+opts.UserAgent)
+	}
+	if len(opts.Headers) > 0 {
+		lines = append(lines, "Headers: "+strings.Join(sortedKeys(opts.Headers), ", "))
+	}
+	switch {
+	case opts.BearerToken != "":
+		lines = append(lines, "Auth: bearer token")
+	case opts.Username != "" || opts.Password != "":
+		lines = append(lines, "Auth: basic, user "+opts.Username)
+	}
+	if len(opts.Cookies) > 0 {
+		lines = append(lines, "Cookies: "+strings.Join(sortedKeys(opts.Cookies), ", "))
+	}
+	if opts.ProxyURL != "" {
+		lines = append(lines, "Proxy: set")
+	}
+	if opts.InsecureSkipVerify {
+		lines = append(lines, "TLS verification: off")
+	}
+	if opts.Timeout > 0 {
+		lines = append(lines, "Timeout: "+opts.Timeout.String())
+	}
+
+	return lines
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := lo.Keys(values)
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,83 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"neuro_scout_bot_v1/internal/botkit"
+)
+
+type IntervalSetter interface {
+	SetFetchInterval(ctx context.Context, sourceID int64, interval time.Duration) error
+}
+
+func ViewCmdSetInterval(intervalSetter IntervalSetter) botkit.ViewFunc {
+	type setIntervalArgs struct {
+		SourceID int64  `json:"source_id"`
+		Interval string `json:"interval"`
+	}
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[setIntervalArgs](update.Message.CommandArguments())
+
+		var interval time.Duration
+		if err == nil {
+			interval, err = parseFetchInterval(args.Interval)
+		}
+
+		if err != nil {
+			helpMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
+				"❌ Incorrect command format. Example: <code>/setinterval {\"source_id\":1,\"interval\":\"30m\"}</code>\n\n"+
+					"Interval uses Go duration format: <code>15m</code>, <code>2h</code>, <code>24h</code>.\n"+
+					"Use <code>\"0\"</code> to go back to the feed's own hints and the default interval.")
+			helpMsg.ParseMode = "HTML"
+			if _, err := bot.Send(helpMsg); err != nil {
+				return err
+			}
+			return err
+		}
+
+		if err := intervalSetter.SetFetchInterval(ctx, args.SourceID, interval); err != nil {
+			errorMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
+				fmt.Sprintf("❌ Error setting fetch interval: %v", err))
+			if _, err := bot.Send(errorMsg); err != nil {
+				return err
+			}
+			return err
+		}
+
+		intervalInfo := "automatic (feed hints or default interval)"
+		if interval > 0 {
+			intervalInfo = interval.String()
+		}
+
+		successMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
+			fmt.Sprintf("✅ Fetch interval successfully updated!\n\nSource ID: %d\nNew interval: %s\n\nThe source will be fetched on the next check.",
+				args.SourceID, intervalInfo))
+		if _, err := bot.Send(successMsg); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func parseFetchInterval(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if interval < 0 {
+		return 0, fmt.Errorf("interval must not be negative")
+	}
+
+	return interval, nil
+}
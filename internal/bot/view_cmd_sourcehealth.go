@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"neuro_scout_bot_v1/internal/botkit"
+	"neuro_scout_bot_v1/internal/botkit/markup"
+	"neuro_scout_bot_v1/internal/model"
+)
+
+func ViewCmdSourceHealth(lister SourceLister, staleAfter time.Duration) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		sources, err := lister.Sources(ctx)
+		if err != nil {
+			return err
+		}
+
+		var (
+			now     = time.Now()
+			reports []string
+		)
+
+		for _, source := range sources {
+			if source.Disabled || source.Health.ConsecutiveFailures > 0 || source.Health.IsThrottled(now) || source.IsStale(staleAfter, now) {
+				reports = append(reports, formatSourceHealth(source, staleAfter, now))
+			}
+		}
+
+		if len(reports) == 0 {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, "✅ All sources are healthy")
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+			return nil
+		}
+
+		msgText := fmt.Sprintf(
+			"Sources that need attention \\(%d of %d\\):\n\n%s",
+			len(reports),
+			len(sources),
+			strings.Join(reports, "\n\n"),
+		)
+
+		for _, chunk := range splitLongMessage(msgText, 4000) {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, chunk)
+			reply.ParseMode = parseModeMarkdownV2
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func formatSourceHealth(source model.Source, staleAfter time.Duration, now time.Time) string {
+	var status string
+	switch {
+	case source.Disabled:
+		status = "⛔ disabled: " + source.DisabledReason
+	case source.Health.ConsecutiveFailures > 0:
+		status = fmt.Sprintf("❌ failing: %d consecutive failures", source.Health.ConsecutiveFailures)
+	case source.Health.IsThrottled(now):
+		status = "🐢 throttled until " + formatHealthTime(source.Health.ThrottledUntil)
+	case source.IsStale(staleAfter, now):
+		status = "💤 stale: no new items for more than " + staleAfter.String()
+	}
+
+	lines := []string{
+		fmt.Sprintf("🌐 *%s* \\(ID: `%d`\\)", markup.EscapeForMarkdown(source.Name), source.ID),
+		markup.EscapeForMarkdown(status),
+		"Last success: " + markup.EscapeForMarkdown(formatHealthTime(source.Health.LastSuccessAt)),
+		"Last new item: " + markup.EscapeForMarkdown(formatHealthTime(source.Health.LastNewItemAt)),
+		fmt.Sprintf("Items in last fetch: %d", source.Health.LastItemsCount),
+	}
+
+	if source.Health.ThrottleCount > 0 {
+		lines = append(lines, fmt.Sprintf(
+			"Throttled %d times, last %s",
+			source.Health.ThrottleCount,
+			markup.EscapeForMarkdown(formatHealthTime(source.Health.LastThrottledAt)),
+		))
+	}
+
+	if source.Health.LastError != "" {
+		lines = append(lines, fmt.Sprintf(
+			"Last error \\(%s\\): %s",
+			markup.EscapeForMarkdown(formatHealthTime(source.Health.LastErrorAt)),
+			markup.EscapeForMarkdown(source.Health.LastError),
+		))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func formatHealthTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+
+	return t.Format("2006-01-02 15:04")
+}
@@ -21,12 +21,29 @@ The bot collects articles from various RSS sources and publishes them to the cha
 <b>Source management:</b>
 • <code>/listsources</code> - view all sources
 • <code>/getsource</code> <i>{id}</i> - get information about a source
-• <code>/addsource</code> <i>{"name":"Name", "url":"URL", "priority":number}</i> - add a new source
+• <code>/addsource</code> <i>{"name":"Name", "url":"URL", "priority":number, "kind":"rss", "config":{}}</i> - add a new source (kind and config are optional)
 • <code>/deletesource</code> <i>{"source_id":number}</i> - delete a source
 • <code>/setpriority</code> <i>{"source_id":number, "priority":number}</i> - set source priority (>=8 for auto-publishing)
+• <code>/setinterval</code> <i>{"source_id":number, "interval":"30m"}</i> - set how often a source is fetched
+• <code>/sourcehealth</code> - list broken and stale sources
+• <code>/enablesource</code> <i>{id}</i> - re-enable a disabled source
+• <code>/importopml</code> - import sources from an OPML file sent with this caption
+• <code>/exportopml</code> - download all sources as an OPML file
+• <code>/sethttp</code> <i>{"source_id":number, "user_agent":"…", "headers":{…}, "basic_auth":{…}, "bearer_token":"…", "cookies":{…}, "proxy_url":"…", "insecure_skip_verify":false, "timeout":"45s"}</i> - set how a source's feed and pages are requested (admins only; send only source_id to reset)
+• <code>/linkchannel</code> <i>{"source_id":number, "channel_id":number}</i> - receive posts of a channel the bot admins as the source's articles
+
+<b>Filter rules:</b>
+• <code>/addrule</code> <i>{"source_id":number, "action":"exclude", "match":"keyword", "pattern":"crypto"}</i> - drop or keep fetched items (match: keyword, regex, category, author; omit source_id for all sources)
+• <code>/listrules</code> - view all filter rules
+• <code>/deleterule</code> <i>{id}</i> - delete a filter rule
+• <code>/testfilter</code> <i>{"action":"include", "match":"regex", "pattern":"LLM", "days":7}</i> - see which recent articles a rule matches
+• <code>/setfilter</code> <i>id "LLM" AND NOT title contains "crypto" AND age &lt; 7d</i> - keep only the source's items that match an expression (fields: title, summary, text, category, author, link, link.domain, source.name, source.priority, age)
+• <code>/dropped</code> <i>{"source_id":number, "stage":"rules", "limit":20}</i> - list fetched items dropped by the processing pipeline and why (all fields optional)
+• <code>/duplicates</code> <i>[limit]</i> - list items dropped as near-duplicates and the articles they matched
 
 <b>Finding and publishing articles:</b>
-• <code>/findarticles</code> <i>{"period":"week", "limit":10}</i> - find articles (period: day, week, month)
+• <code>/findarticles</code> <i>{"period":"week", "limit":10, "tag":"cs.LG"}</i> - find articles (period: day, week, month; tag is optional)
+• <code>/submit</code> <i>url [note] [--next]</i> - queue an article by hand; channel admins can also just send the bot a link
 • <code>/publishtochannel</code> <i>{"period":"week", "limit":5}</i> - publish articles to the channel
 
 <b>OpenAI settings (for summary generation):</b>
@@ -52,6 +69,7 @@ The bot collects articles from various RSS sources and publishes them to the cha
 <code>/publishtochannel {"period":"week","limit":3}</code>
 <code>/addsource {"name":"Golang Blog","url":"https://go.dev/blog/feed.atom","priority":5}</code>
 <code>/setpriority {"source_id":1,"priority":8}</code>
+<code>/setinterval {"source_id":1,"interval":"2h"}</code>
 
 <i>Click on a command to copy it and use it immediately.</i>`
 
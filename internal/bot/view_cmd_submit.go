@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"neuro_scout_bot_v1/internal/botkit"
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+	sourcelib "neuro_scout_bot_v1/internal/source"
+)
+
+const publishNextFlag = "--next"
+
+type ManualSourceProvider interface {
+	ManualSource(ctx context.Context) (model.Source, error)
+}
+
+type ArticleStorer interface {
+	Store(ctx context.Context, article model.Article) (model.StoreResult, error)
+}
+
+type submission struct {
+	Link        string
+	Note        string
+	PublishNext bool
+}
+
+// ViewCmdSubmit queues an article found by hand: /submit <url> [note] [--next].
+func ViewCmdSubmit(sources ManualSourceProvider, articles ArticleStorer, limiter *ratelimit.Limiter) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		sub, err := parseSubmitArgs(update.Message.CommandArguments())
+		if err != nil {
+			helpMsg := tgbotapi.NewMessage(update.Message.Chat.ID,
+				"❌ Incorrect command format. Example: <code>/submit https://example.com/post Worth a read --next</code>\n\n"+
+					"The note is optional. Add <code>--next</code> to publish the article next.")
+			helpMsg.ParseMode = "HTML"
+			if _, err := bot.Send(helpMsg); err != nil {
+				return err
+			}
+			return err
+		}
+
+		return submitArticle(ctx, bot, update.Message.Chat.ID, sources, articles, limiter, sub)
+	}
+}
+
+// ViewMessageSubmit queues the first link of a plain or forwarded message.
+// Messages without links are ignored.
+func ViewMessageSubmit(sources ManualSourceProvider, articles ArticleStorer, limiter *ratelimit.Limiter) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		link := sourcelib.MessageLink(update.Message)
+		if link == "" {
+			return nil
+		}
+
+		return submitArticle(ctx, bot, update.Message.Chat.ID, sources, articles, limiter, submission{Link: link})
+	}
+}
+
+func parseSubmitArgs(args string) (submission, error) {
+	var (
+		sub  submission
+		note []string
+	)
+
+	for _, field := range strings.Fields(args) {
+		switch {
+		case field == publishNextFlag:
+			sub.PublishNext = true
+		case sub.Link == "":
+			sub.Link = field
+		default:
+			note = append(note, field)
+		}
+	}
+
+	u, err := url.ParseRequestURI(sub.Link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return submission{}, fmt.Errorf("invalid url %q", sub.Link)
+	}
+
+	sub.Note = strings.Join(note, " ")
+
+	return sub, nil
+}
+
+func submitArticle(
+	ctx context.Context,
+	bot *tgbotapi.BotAPI,
+	chatID int64,
+	sources ManualSourceProvider,
+	articles ArticleStorer,
+	limiter *ratelimit.Limiter,
+	sub submission,
+) error {
+	manual, err := sources.ManualSource(ctx)
+	if err != nil {
+		errorMsg := tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ Error submitting article: %v", err))
+		if _, err := bot.Send(errorMsg); err != nil {
+			return err
+		}
+		return err
+	}
+
+	metadata, err := sourcelib.FetchPageMetadata(ctx, limiter, sub.Link)
+	if err != nil {
+		// The notifier can still read the page when it is posted.
+		log.Printf("[WARN] failed to read metadata of submitted page %s: %v", sub.Link, err)
+	}
+
+	article := model.Article{
+		SourceID:    manual.ID,
+		Title:       metadata.Title,
+		Link:        sub.Link,
+		Summary:     strings.TrimSpace(sub.Note + "\n\n" + metadata.Description),
+		PublishNext: sub.PublishNext,
+		PublishedAt: time.Now().UTC(),
+	}
+
+	if article.Title == "" {
+		article.Title = sub.Link
+	}
+
+	result, err := articles.Store(ctx, article)
+	if err != nil {
+		errorMsg := tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ Error submitting article: %v", err))
+		if _, err := bot.Send(errorMsg); err != nil {
+			return err
+		}
+		return err
+	}
+
+	var text string
+	switch result {
+	case model.ArticleInserted:
+		queueInfo := "It is queued for publishing."
+		if sub.PublishNext {
+			queueInfo = "It will be published next."
+		}
+		text = fmt.Sprintf("✅ Article submitted!\n\nTitle: %s\nLink: %s\n\n%s", article.Title, article.Link, queueInfo)
+	case model.ArticleUpdated:
+		text = fmt.Sprintf("ℹ️ This article is already stored. It will be published next.\n\nLink: %s", article.Link)
+	default:
+		text = fmt.Sprintf("ℹ️ This article is already stored, nothing changed.\n\nLink: %s", article.Link)
+	}
+
+	if _, err := bot.Send(tgbotapi.NewMessage(chatID, text)); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSubmitArgs(t *testing.T) {
+	sub, err := parseSubmitArgs("https://example.com/post  Great  read --next")
+	require.NoError(t, err)
+	assert.Equal(t, submission{Link: "https://example.com/post", Note: "Great read", PublishNext: true}, sub)
+
+	sub, err = parseSubmitArgs("--next https://example.com/post")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/post", sub.Link)
+	assert.True(t, sub.PublishNext)
+
+	_, err = parseSubmitArgs("")
+	assert.Error(t, err)
+
+	_, err = parseSubmitArgs("example.com/post")
+	assert.Error(t, err, "the scheme is required")
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"log"
 	"runtime/debug"
+	"strings"
 	"time"
+	"unicode/utf16"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -132,6 +134,29 @@ func min(a, b int64) int64 {
 	return b
 }
 
+// MessageCommand returns the command of a message without the leading slash,
+// also when it is the caption of a file, or an empty string if there is none.
+func MessageCommand(msg *tgbotapi.Message) string {
+	if msg.IsCommand() {
+		return msg.Command()
+	}
+
+	if len(msg.CaptionEntities) == 0 {
+		return ""
+	}
+
+	entity := msg.CaptionEntities[0]
+	caption := utf16.Encode([]rune(msg.Caption))
+	if entity.Type != "bot_command" || entity.Offset != 0 || entity.Length < 2 || entity.Length > len(caption) {
+		return ""
+	}
+
+	command := string(utf16.Decode(caption[1:entity.Length]))
+	command, _, _ = strings.Cut(command, "@")
+
+	return command
+}
+
 type ViewFunc func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error
 
 func ParseJSON[T any](args string) (T, error) {
@@ -0,0 +1,211 @@
+// Package canonical turns article links into a canonical form, so the same
+// story reached through different links is recognized as one article.
+package canonical
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// trackingParams are query parameters that identify the campaign or the
+// click, not the page.
+var trackingParams = map[string]bool{
+	"fbclid":               true,
+	"gclid":                true,
+	"dclid":                true,
+	"yclid":                true,
+	"msclkid":              true,
+	"igshid":               true,
+	"mc_cid":               true,
+	"mc_eid":               true,
+	"_hsenc":               true,
+	"_hsmi":                true,
+	"mkt_tok":              true,
+	"ref":                  true,
+	"ref_src":              true,
+	"ref_url":              true,
+	"cmpid":                true,
+	"ncid":                 true,
+	"sr_share":             true,
+	"spm":                  true,
+	"source":               true,
+	"rss":                  true,
+	"__twitter_impression": true,
+}
+
+// trackingPrefixes are prefixes of tracking parameter names.
+var trackingPrefixes = []string{"utm_", "pk_", "mtm_", "oly_"}
+
+// unwrapParams are redirector hosts that carry the target in a query
+// parameter, with the names of the parameter.
+var unwrapParams = map[string][]string{
+	"google.com":      {"url", "q"},
+	"news.google.com": {"url"},
+	"l.facebook.com":  {"u"},
+	"lm.facebook.com": {"u"},
+	"out.reddit.com":  {"url"},
+	"slack-redir.net": {"url"},
+	"l.instagram.com": {"u"},
+	"away.vk.com":     {"to"},
+	"t.umblr.com":     {"z"},
+	"href.li":         {},
+}
+
+// maxUnwraps bounds nested redirector links.
+const maxUnwraps = 3
+
+// Clean returns the canonical form of a link without touching the network.
+// Links that do not parse are returned trimmed.
+func Clean(link string) string {
+	link = strings.TrimSpace(link)
+
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return link
+	}
+
+	for i := 0; i < maxUnwraps; i++ {
+		target, ok := unwrap(u)
+		if !ok {
+			break
+		}
+		u = target
+	}
+
+	if u.Scheme == "http" || u.Scheme == "https" {
+		u.Scheme = "https"
+	}
+
+	u.Host = strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	u.Host = strings.TrimSuffix(strings.TrimSuffix(u.Host, ":80"), ":443")
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	if u.Path != "" {
+		cleaned := path.Clean(u.Path)
+		if cleaned == "/" || cleaned == "." {
+			cleaned = ""
+		}
+		u.Path = cleaned
+		u.RawPath = ""
+	}
+
+	u.RawQuery = cleanQuery(u.Query())
+
+	return u.String()
+}
+
+// unwrap returns the target of a redirector link.
+func unwrap(u *url.URL) (*url.URL, bool) {
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+
+	params, ok := unwrapParams[host]
+	if !ok {
+		return nil, false
+	}
+
+	// href.li puts the target right after the question mark.
+	if host == "href.li" {
+		target, err := url.Parse(u.RawQuery)
+		return target, err == nil && target.Host != ""
+	}
+
+	if host == "google.com" && u.Path != "/url" {
+		return nil, false
+	}
+
+	if host == "news.google.com" && u.Query().Get("url") == "" {
+		return googleNewsTarget(u)
+	}
+
+	query := u.Query()
+	for _, param := range params {
+		target, err := url.Parse(query.Get(param))
+		if err == nil && target.Host != "" && (target.Scheme == "http" || target.Scheme == "https") {
+			return target, true
+		}
+	}
+
+	return nil, false
+}
+
+// googleNewsTarget decodes the URL in a Google News article ID. Newer opaque
+// IDs do not decode.
+func googleNewsTarget(u *url.URL) (*url.URL, bool) {
+	_, id, ok := strings.Cut(u.Path, "/articles/")
+	if !ok {
+		return nil, false
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(id, "="))
+	if err != nil || len(data) < 3 || data[0] != 0x08 {
+		return nil, false
+	}
+
+	_, n := binary.Uvarint(data[1:])
+	if n <= 0 || 1+n >= len(data) || data[1+n] != 0x22 {
+		return nil, false
+	}
+	data = data[2+n:]
+
+	size, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) < size {
+		return nil, false
+	}
+
+	target, err := url.Parse(string(data[n : n+int(size)]))
+	if err != nil || target.Host == "" || (target.Scheme != "http" && target.Scheme != "https") {
+		return nil, false
+	}
+
+	return target, true
+}
+
+func cleanQuery(query url.Values) string {
+	for name := range query {
+		if isTrackingParam(name) {
+			query.Del(name)
+		}
+	}
+
+	if len(query) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		values := query[name]
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, url.QueryEscape(name)+"="+url.QueryEscape(value))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+func isTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	if trackingParams[name] {
+		return true
+	}
+
+	for _, prefix := range trackingPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
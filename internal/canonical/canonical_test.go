@@ -0,0 +1,116 @@
+package canonical
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"https://example.com/post", "https://example.com/post"},
+		{"http://www.Example.com/post/", "https://example.com/post"},
+		{"https://example.com:443/a/../post#comments", "https://example.com/post"},
+		{"https://example.com/", "https://example.com"},
+		{
+			"https://example.com/post?utm_source=rss&utm_medium=feed&id=7&fbclid=abc&b=2",
+			"https://example.com/post?b=2&id=7",
+		},
+		{
+			"https://www.google.com/url?sa=t&url=https%3A%2F%2Fexample.com%2Fpost%3Futm_campaign%3Dx",
+			"https://example.com/post",
+		},
+		{"https://l.facebook.com/l.php?u=http%3A%2F%2Fexample.com%2Fpost%2F&h=AT0", "https://example.com/post"},
+		{"https://href.li/?https://example.com/post", "https://example.com/post"},
+		{"https://www.google.com/search?q=llm", "https://google.com/search?q=llm"},
+		{
+			"https://news.google.com/rss/articles/CBMiQ2h0dHBzOi8vd3d3LnRoZXZlcmdlLmNvbS8yMDI0LzEvMTAvMjQwMzI1NDcvb3BlbmFpLWdwdC1zdG9yZS1sYXVuY2jSAQA?oc=5",
+			"https://theverge.com/2024/1/10/24032547/openai-gpt-store-launch",
+		},
+		{"  not a url  ", "not a url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			assert.Equal(t, tt.want, Clean(tt.link))
+		})
+	}
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	var requests atomic.Int32
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head></head></html>`))
+	}))
+	defer site.Close()
+
+	u, err := url.Parse(site.URL)
+	require.NoError(t, err)
+	base := "https://" + u.Host
+
+	// The redirector is reached as localhost, so that it is told apart from
+	// the site on 127.0.0.1.
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch {
+		case r.URL.Path == "/~r/feed/123":
+			http.Redirect(w, r, site.URL+"/story?utm_source=feedburner", http.StatusMovedPermanently)
+		case strings.HasPrefix(r.URL.Path, "/rss/articles/"):
+			// The opaque article IDs of Google News lead to a page that
+			// redirects with JavaScript.
+			_, _ = w.Write([]byte(`<html><body><c-wiz data-n-au="` + site.URL + `/2025/06/story?utm_source=gn"></c-wiz></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer redirector.Close()
+	redirectorURL := strings.Replace(redirector.URL, "127.0.0.1", "localhost", 1)
+
+	resolver := NewResolver(nil)
+
+	resolved, err := resolver.Resolve(context.Background(), site.URL+"/plain/?utm_medium=x")
+	require.NoError(t, err)
+	assert.Equal(t, base+"/plain", resolved)
+	assert.Zero(t, requests.Load(), "links that are not redirectors are not loaded")
+
+	resolver.redirectors = map[string]bool{"localhost": true}
+
+	resolved, err = resolver.Resolve(context.Background(), redirectorURL+"/~r/feed/123")
+	require.NoError(t, err)
+	assert.Equal(t, base+"/story", resolved, "redirects are followed")
+
+	resolved, err = resolver.Resolve(context.Background(), redirectorURL+"/rss/articles/AU_yqLPxhB3Q?oc=5")
+	require.NoError(t, err)
+	assert.Equal(t, base+"/2025/06/story", resolved, "the target is read from the redirector page")
+
+	resolved, err = resolver.Resolve(context.Background(), redirectorURL+"/missing?utm_medium=x")
+	assert.Error(t, err)
+	assert.Equal(t, "https://"+strings.TrimPrefix(redirectorURL, "http://")+"/missing", resolved, "the cleaned link is returned on errors")
+}
+
+func TestPageLink(t *testing.T) {
+	story, err := url.Parse("http://example.com/story")
+	require.NoError(t, err)
+
+	link, ok := PageLink([]byte(`<html><head><link rel="canonical" href="/2025/06/story/?utm_source=x"></head></html>`), story)
+	require.True(t, ok)
+	assert.Equal(t, "https://example.com/2025/06/story", link)
+
+	_, ok = PageLink([]byte(`<html><head><link rel="canonical" href="https://example.com/"></head></html>`), story)
+	assert.False(t, ok, "a canonical front page is ignored")
+
+	_, ok = PageLink([]byte(`<html><head></head></html>`), story)
+	assert.False(t, ok)
+}
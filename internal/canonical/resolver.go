@@ -0,0 +1,156 @@
+package canonical
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"neuro_scout_bot_v1/internal/httpclient"
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+// maxPageSize bounds how much of a redirector page is read.
+const maxPageSize = 1 << 20
+
+// redirectHosts are link shorteners and feed proxies whose target is only
+// known by following them.
+var redirectHosts = map[string]bool{
+	"feedproxy.google.com": true,
+	"news.google.com":      true,
+	"feeds.feedburner.com": true,
+	"t.co":                 true,
+	"bit.ly":               true,
+	"buff.ly":              true,
+	"ow.ly":                true,
+	"lnkd.in":              true,
+	"dlvr.it":              true,
+	"trib.al":              true,
+	"tinyurl.com":          true,
+	"goo.gl":               true,
+	"rebrand.ly":           true,
+}
+
+// Resolver finds where links through redirectors end up. Other links are
+// only cleaned.
+type Resolver struct {
+	client      *http.Client
+	redirectors map[string]bool
+}
+
+// NewResolver returns a resolver whose requests go through the limiter,
+// which can be nil.
+func NewResolver(limiter *ratelimit.Limiter) *Resolver {
+	return &Resolver{
+		client:      httpclient.New(model.HTTPOptions{}, limiter),
+		redirectors: redirectHosts,
+	}
+}
+
+// Resolve returns the canonical form of the link, following known
+// redirectors. On error the cleaned link is still returned.
+func (r *Resolver) Resolve(ctx context.Context, link string) (string, error) {
+	cleaned := Clean(link)
+
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return cleaned, nil
+	}
+
+	// Clean already unwraps the redirector links it can decode.
+	if !r.isRedirector(cleaned) {
+		return cleaned, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return cleaned, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", httpclient.UserAgent)
+	req.Header.Set("Accept", "text/html, application/xhtml+xml")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return cleaned, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return cleaned, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	final := resp.Request.URL
+	if !r.isRedirector(final.String()) {
+		return Clean(final.String()), nil
+	}
+
+	// Google News answers with a page that redirects with JavaScript; the
+	// target is in the page.
+	page, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return cleaned, err
+	}
+
+	if target, ok := pageTarget(page, final); ok && !r.isRedirector(target) {
+		return target, nil
+	}
+
+	return cleaned, fmt.Errorf("no target found on redirector page %s", final)
+}
+
+func (r *Resolver) isRedirector(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && r.redirectors[strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")]
+}
+
+// pageTarget finds the article a redirector page leads to.
+func pageTarget(page []byte, base *url.URL) (string, bool) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
+	if err != nil {
+		return "", false
+	}
+
+	if href, ok := doc.Find("[data-n-au]").First().Attr("data-n-au"); ok {
+		if target, err := base.Parse(strings.TrimSpace(href)); err == nil && target.Host != "" {
+			return Clean(target.String()), true
+		}
+	}
+
+	return PageLink(page, base)
+}
+
+// PageLink returns the cleaned <link rel="canonical"> of a page loaded from
+// base. Links to the site's front page are ignored.
+func PageLink(page []byte, base *url.URL) (string, bool) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
+	if err != nil {
+		return "", false
+	}
+
+	href, ok := doc.Find(`link[rel~="canonical"][href]`).First().Attr("href")
+	if !ok {
+		return "", false
+	}
+
+	link, err := base.Parse(strings.TrimSpace(href))
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		return "", false
+	}
+
+	if isFrontPage(link) && !isFrontPage(base) {
+		return "", false
+	}
+
+	return Clean(link.String()), true
+}
+
+func isFrontPage(u *url.URL) bool {
+	return strings.Trim(u.Path, "/") == "" && u.RawQuery == ""
+}
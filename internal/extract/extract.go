@@ -0,0 +1,202 @@
+// Package extract downloads article pages and extracts their readable text
+// and lead image, so articles can be summarized without loading them again.
+package extract
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/go-shiori/go-readability"
+
+	"neuro_scout_bot_v1/internal/canonical"
+	"neuro_scout_bot_v1/internal/httpclient"
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+	sourcelib "neuro_scout_bot_v1/internal/source"
+)
+
+// maxPageSize bounds how much of a page is read.
+const maxPageSize = 5 << 20
+
+// minTextLength is the length below which extracted text is not usable.
+const minTextLength = 20
+
+// fullTextLength is the length from which text from the feed is taken to
+// be the whole article rather than a teaser.
+const fullTextLength = 1000
+
+// ErrNoText is returned when a page or an article has no usable text.
+var ErrNoText = errors.New("no text to extract")
+
+// PermanentError is an extraction failure that retrying will not fix, such
+// as a page that is gone.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Result is what is extracted from a page.
+type Result struct {
+	Text     string
+	ImageURL string
+	// CanonicalLink is the page's <link rel="canonical"> in canonical form,
+	// empty when the page has none.
+	CanonicalLink string
+}
+
+type Extractor struct {
+	client  *http.Client
+	limiter *ratelimit.Limiter
+}
+
+// NewExtractor returns an extractor whose requests go through the limiter,
+// which can be nil.
+func NewExtractor(limiter *ratelimit.Limiter) *Extractor {
+	return &Extractor{
+		client:  httpclient.New(model.HTTPOptions{}, limiter),
+		limiter: limiter,
+	}
+}
+
+// clientFor returns the client with the source's HTTP options, whose
+// secrets go to the source's own hosts only.
+func (e *Extractor) clientFor(source model.Source) *http.Client {
+	if source.HTTP.IsZero() {
+		return e.client
+	}
+
+	return httpclient.New(source.HTTP, e.limiter, sourcelib.HTTPHosts(source)...)
+}
+
+// Extract returns the readable text, lead image and canonical link of the
+// article's page. Failures that retrying will not fix are *PermanentError.
+func (e *Extractor) Extract(ctx context.Context, link string, source model.Source) (Result, error) {
+	pageURL, err := url.Parse(link)
+	if err != nil || (pageURL.Scheme != "http" && pageURL.Scheme != "https") {
+		return Result{}, &PermanentError{Err: fmt.Errorf("invalid link %q", link)}
+	}
+
+	ctx, err = e.limiter.Acquire(ctx, ratelimit.HostOf(link))
+	if err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return Result{}, &PermanentError{Err: fmt.Errorf("failed to create request: %w", err)}
+	}
+
+	req.Header.Set("User-Agent", httpclient.UserAgent)
+	req.Header.Set("Accept", "text/html, application/xhtml+xml")
+
+	resp, err := e.clientFor(source).Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	if err := ratelimit.CheckResponse(resp); err != nil {
+		var status *ratelimit.StatusError
+		if errors.As(err, &status) && !status.Temporary() {
+			return Result{}, &PermanentError{Err: err}
+		}
+		return Result{}, err
+	}
+
+	page, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return Result{}, err
+	}
+
+	doc, err := readability.FromReader(bytes.NewReader(page), resp.Request.URL)
+	if err != nil {
+		return Result{}, &PermanentError{Err: fmt.Errorf("failed to parse page: %w", err)}
+	}
+
+	text := cleanupText(doc.TextContent)
+	if len(text) < minTextLength {
+		return Result{}, &PermanentError{Err: ErrNoText}
+	}
+
+	canonicalLink, _ := canonical.PageLink(page, resp.Request.URL)
+
+	return Result{
+		Text:          text,
+		ImageURL:      absoluteURL(resp.Request.URL, doc.Image),
+		CanonicalLink: canonicalLink,
+	}, nil
+}
+
+// ArticleText returns the extracted text of the article, or else its feed
+// content or summary stripped of markup.
+func ArticleText(article model.Article) (string, error) {
+	if len(article.Text) >= minTextLength {
+		return article.Text, nil
+	}
+
+	for _, html := range []string{article.Content, article.Summary} {
+		if strings.TrimSpace(html) == "" {
+			continue
+		}
+
+		doc, err := readability.FromReader(strings.NewReader(html), nil)
+		if err != nil {
+			continue
+		}
+
+		if text := cleanupText(doc.TextContent); len(text) >= minTextLength {
+			return text, nil
+		}
+	}
+
+	return "", ErrNoText
+}
+
+// HasOwnText reports whether the article's page needs no extraction, as for
+// papers, text posts and full text feeds.
+func HasOwnText(article model.Article) bool {
+	if article.PrimaryCategory != "" {
+		return true
+	}
+
+	text, err := ArticleText(article)
+	if err != nil {
+		return false
+	}
+
+	return article.CommentsLink != "" || len(text) >= fullTextLength
+}
+
+var redundantNewLines = regexp.MustCompile(`\n{3,}`)
+
+func cleanupText(text string) string {
+	return strings.TrimSpace(redundantNewLines.ReplaceAllString(text, "\n"))
+}
+
+func absoluteURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+
+	return u.String()
+}
@@ -0,0 +1,239 @@
+package extract
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>New model released</title>
+  <meta property="og:image" content="/images/lead.png">
+  <link rel="canonical" href="/2025/06/new-model/">
+</head>
+<body>
+  <nav><a href="/">Home</a></nav>
+  <article>
+    <h1>New model released</h1>
+    <p>The lab released a new language model today. It is smaller than the previous one and answers questions about twice as fast on the same hardware.</p>
+    <p>The weights are available under an open license, and the lab published the evaluation results together with the training recipe.</p>
+  </article>
+</body>
+</html>`
+
+func TestExtractor_Extract(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/post":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(articlePage))
+		case "/busy":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	extractor := NewExtractor(nil)
+	ctx := context.Background()
+
+	result, err := extractor.Extract(ctx, server.URL+"/post", model.Source{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "released a new language model today")
+	assert.NotContains(t, result.Text, "Home")
+	assert.Equal(t, server.URL+"/images/lead.png", result.ImageURL)
+	assert.Equal(t, "https://"+strings.TrimPrefix(server.URL, "http://")+"/2025/06/new-model", result.CanonicalLink)
+
+	var permanent *PermanentError
+
+	_, err = extractor.Extract(ctx, server.URL+"/gone", model.Source{})
+	assert.ErrorAs(t, err, &permanent, "a missing page is not retried")
+
+	_, err = extractor.Extract(ctx, server.URL+"/busy", model.Source{})
+	require.Error(t, err)
+	assert.False(t, errors.As(err, &permanent), "a busy server is retried")
+
+	_, err = extractor.Extract(ctx, "ftp://example.com/post", model.Source{})
+	assert.ErrorAs(t, err, &permanent)
+}
+
+func TestExtractor_ExtractSourceSecrets(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(articlePage))
+	}))
+	defer server.Close()
+
+	extractor := NewExtractor(nil)
+	ctx := context.Background()
+	options := model.HTTPOptions{BearerToken: "s3cret"}
+
+	_, err := extractor.Extract(ctx, server.URL+"/post", model.Source{FeedURL: "https://feed.example.com/rss", HTTP: options})
+	require.NoError(t, err)
+	assert.Empty(t, authorization, "the token must not go to the site an article links to")
+
+	_, err = extractor.Extract(ctx, server.URL+"/post", model.Source{FeedURL: server.URL + "/feed", HTTP: options})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer s3cret", authorization, "the token goes to the feed's own host")
+}
+
+func TestArticleText(t *testing.T) {
+	text, err := ArticleText(model.Article{
+		Text:    "The text extracted from the page.",
+		Content: "<p>The content from the feed.</p>",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "The text extracted from the page.", text)
+
+	text, err = ArticleText(model.Article{
+		Content: "<p>The content from the feed, long enough to use.</p>",
+		Summary: "<p>The summary from the feed.</p>",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "The content from the feed, long enough to use.", text)
+
+	text, err = ArticleText(model.Article{Summary: "<p>The summary from the feed.</p>"})
+	require.NoError(t, err)
+	assert.Equal(t, "The summary from the feed.", text)
+
+	_, err = ArticleText(model.Article{Summary: "<p>Short</p>"})
+	assert.ErrorIs(t, err, ErrNoText)
+}
+
+type memoryArticles struct {
+	mu        sync.Mutex
+	pending   []model.Article
+	saved     map[int64]Result
+	failures  map[int64]time.Time
+	postponed map[int64]time.Time
+}
+
+func (m *memoryArticles) PendingExtractions(context.Context, uint64) ([]model.Article, error) {
+	return m.pending, nil
+}
+
+func (m *memoryArticles) SaveExtraction(_ context.Context, articleID int64, text, leadImageURL, canonicalLink string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.saved[articleID] = Result{Text: text, ImageURL: leadImageURL, CanonicalLink: canonicalLink}
+	return nil
+}
+
+func (m *memoryArticles) RecordExtractionFailure(_ context.Context, articleID int64, _ string, retryAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.failures[articleID] = retryAt
+	return nil
+}
+
+func (m *memoryArticles) PostponeExtraction(_ context.Context, articleID int64, retryAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.postponed[articleID] = retryAt
+	return nil
+}
+
+type stubExtractor map[string]error
+
+type memorySources map[int64]model.Source
+
+func (m memorySources) SourceByID(_ context.Context, id int64) (*model.Source, error) {
+	source, ok := m[id]
+	if !ok {
+		return nil, errors.New("source not found")
+	}
+	return &source, nil
+}
+
+func (s stubExtractor) Extract(_ context.Context, link string, _ model.Source) (Result, error) {
+	if err := s[link]; err != nil {
+		return Result{}, err
+	}
+	return Result{Text: "Text of " + link}, nil
+}
+
+func TestHasOwnText(t *testing.T) {
+	selfPost := model.Article{
+		Link:         "https://www.reddit.com/r/golang/comments/1/question",
+		CommentsLink: "https://www.reddit.com/r/golang/comments/1/question",
+		Summary:      "How do you structure background workers in Go services?",
+	}
+	assert.True(t, HasOwnText(selfPost), "posts bring their own text")
+
+	assert.True(t, HasOwnText(model.Article{
+		Link:            "https://arxiv.org/abs/2506.01234",
+		Authors:         []string{"A. Researcher"},
+		PrimaryCategory: "cs.CL",
+		Summary:         "We study sparse attention.",
+	}), "papers are summarized from the abstract, not their abs page")
+
+	assert.False(t, HasOwnText(model.Article{
+		Link:         "https://example.com/story",
+		CommentsLink: "https://news.ycombinator.com/item?id=1",
+	}), "link posts without text need their page")
+
+	assert.False(t, HasOwnText(model.Article{
+		Link:    "https://example.com/story",
+		Summary: "<p>A teaser of the story, with a link to read more.</p>",
+	}), "feed teasers need the page")
+
+	assert.True(t, HasOwnText(model.Article{
+		Link:    "https://example.com/story",
+		Content: "<p>" + strings.Repeat("The whole story is in the feed. ", 40) + "</p>",
+	}), "full text feeds need no page")
+}
+
+func TestWorker_Run(t *testing.T) {
+	articles := &memoryArticles{
+		pending: []model.Article{
+			{ID: 1, Link: "https://example.com/ok"},
+			{ID: 2, Link: "https://example.com/busy", ExtractionAttempts: 1},
+			{ID: 3, Link: "https://example.com/gone"},
+			{ID: 4, Link: "https://example.com/busy", ExtractionAttempts: 2},
+			{ID: 5, Link: "https://paused.example.com/post", ExtractionAttempts: 2},
+		},
+		saved:     make(map[int64]Result),
+		failures:  make(map[int64]time.Time),
+		postponed: make(map[int64]time.Time),
+	}
+	pausedUntil := time.Now().Add(time.Hour).Truncate(time.Second)
+	extractor := stubExtractor{
+		"https://paused.example.com/post": &ratelimit.ThrottledError{Host: "paused.example.com", Until: pausedUntil},
+		"https://example.com/busy":        errors.New("unexpected response status: 503 Service Unavailable"),
+		"https://example.com/gone":        &PermanentError{Err: errors.New("unexpected response status: 404 Not Found")},
+	}
+
+	start := time.Now()
+	require.NoError(t, NewWorker(articles, memorySources{}, extractor, 2, 3).Run(context.Background()))
+
+	assert.Equal(t, map[int64]Result{1: {Text: "Text of https://example.com/ok"}}, articles.saved)
+	require.Len(t, articles.failures, 3)
+
+	retryAt := articles.failures[2]
+	assert.False(t, retryAt.Before(start.Add(2*retryBackoff)), "the delay doubles after every attempt")
+	assert.True(t, retryAt.Before(time.Now().Add(2*retryBackoff+time.Second)))
+
+	assert.True(t, articles.failures[3].IsZero(), "permanent failures are not retried")
+	assert.True(t, articles.failures[4].IsZero(), "the last attempt gives up")
+
+	assert.Equal(t, map[int64]time.Time{5: pausedUntil}, articles.postponed, "a paused host costs no attempt")
+}
@@ -0,0 +1,196 @@
+package extract
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+const (
+	// pollInterval is how often the worker looks for articles to extract.
+	pollInterval = 30 * time.Second
+	// batchPerWorker is how many articles each worker gets per poll.
+	batchPerWorker = 5
+	// retryBackoff is the wait before the first retry; it doubles after
+	// every failed attempt.
+	retryBackoff = 5 * time.Minute
+)
+
+type ArticleStore interface {
+	PendingExtractions(ctx context.Context, limit uint64) ([]model.Article, error)
+	SaveExtraction(ctx context.Context, articleID int64, text, leadImageURL, canonicalLink string) error
+	RecordExtractionFailure(ctx context.Context, articleID int64, extractErr string, retryAt time.Time) error
+	PostponeExtraction(ctx context.Context, articleID int64, retryAt time.Time) error
+}
+
+// SourceProvider looks up the source of an article, for its HTTP options.
+type SourceProvider interface {
+	SourceByID(ctx context.Context, id int64) (*model.Source, error)
+}
+
+// PageExtractor extracts the text of a page.
+type PageExtractor interface {
+	Extract(ctx context.Context, link string, source model.Source) (Result, error)
+}
+
+// Worker extracts the text of new articles in the background, a bounded
+// number at a time, and retries failed attempts with a growing delay.
+type Worker struct {
+	articles    ArticleStore
+	sources     SourceProvider
+	extractor   PageExtractor
+	concurrency int
+	maxAttempts int
+}
+
+func NewWorker(articles ArticleStore, sources SourceProvider, extractor PageExtractor, concurrency, maxAttempts int) *Worker {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	return &Worker{
+		articles:    articles,
+		sources:     sources,
+		extractor:   extractor,
+		concurrency: concurrency,
+		maxAttempts: maxAttempts,
+	}
+}
+
+func (w *Worker) Start(ctx context.Context) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Run(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("[ERROR] failed to extract article texts: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run extracts a batch of the articles that are due.
+func (w *Worker) Run(ctx context.Context) error {
+	articles, err := w.articles.PendingExtractions(ctx, uint64(w.concurrency*batchPerWorker))
+	if err != nil {
+		return err
+	}
+
+	sources := w.sourcesOf(ctx, articles)
+
+	var (
+		wg   sync.WaitGroup
+		jobs = make(chan model.Article)
+	)
+
+	for i := 0; i < w.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for article := range jobs {
+				w.extract(ctx, article, sources[article.SourceID])
+			}
+		}()
+	}
+
+dispatch:
+	for _, article := range articles {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- article:
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// sourcesOf looks up the sources of the articles. Articles whose source
+// cannot be found are extracted with the default HTTP options.
+func (w *Worker) sourcesOf(ctx context.Context, articles []model.Article) map[int64]model.Source {
+	sources := make(map[int64]model.Source)
+	for _, article := range articles {
+		if _, ok := sources[article.SourceID]; ok {
+			continue
+		}
+
+		source, err := w.sources.SourceByID(ctx, article.SourceID)
+		if err != nil || source == nil {
+			log.Printf("[WARN] failed to get source %d of article %d: %v", article.SourceID, article.ID, err)
+			sources[article.SourceID] = model.Source{}
+			continue
+		}
+
+		sources[article.SourceID] = *source
+	}
+
+	return sources
+}
+
+func (w *Worker) extract(ctx context.Context, article model.Article, source model.Source) {
+	result, err := w.extractor.Extract(ctx, article.Link, source)
+	if err == nil {
+		if err := w.articles.SaveExtraction(ctx, article.ID, result.Text, result.ImageURL, result.CanonicalLink); err != nil {
+			log.Printf("[ERROR] failed to save extracted text of article %d: %v", article.ID, err)
+		}
+		return
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	// A host paused before the page was asked for got no request, so the
+	// article is only put off, without counting an attempt.
+	var throttled *ratelimit.ThrottledError
+	if errors.As(err, &throttled) {
+		log.Printf("[INFO] postponing extraction of %s until %s: %v", article.Link, throttled.Until.Format(time.RFC3339), err)
+		if err := w.articles.PostponeExtraction(ctx, article.ID, throttled.Until); err != nil {
+			log.Printf("[ERROR] failed to postpone extraction of article %d: %v", article.ID, err)
+		}
+		return
+	}
+
+	attempt := article.ExtractionAttempts + 1
+
+	var retryAt time.Time
+	var permanent *PermanentError
+	if !errors.As(err, &permanent) && attempt < w.maxAttempts {
+		retryAt = time.Now().Add(retryBackoff << (attempt - 1))
+
+		// A throttling host is not asked again before it allows it.
+		if until, ok := ratelimit.ThrottledUntil(err); ok && until.After(retryAt) {
+			retryAt = until
+		}
+	}
+
+	if retryAt.IsZero() {
+		log.Printf("[WARN] giving up extracting %s after %d attempts: %v", article.Link, attempt, err)
+	} else {
+		log.Printf("[WARN] failed to extract %s, retrying at %s: %v", article.Link, retryAt.Format(time.RFC3339), err)
+	}
+
+	if err := w.articles.RecordExtractionFailure(ctx, article.ID, err.Error(), retryAt); err != nil {
+		log.Printf("[ERROR] failed to record extraction failure of article %d: %v", article.ID, err)
+	}
+}
@@ -4,21 +4,52 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"neuro_scout_bot_v1/internal/canonical"
+	"neuro_scout_bot_v1/internal/extract"
+	"neuro_scout_bot_v1/internal/filter"
+	"neuro_scout_bot_v1/internal/fingerprint"
 	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
 	sourcelib "neuro_scout_bot_v1/internal/source"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"go.tomakado.io/containers/set"
 )
 
 type ArticleStorage interface {
-	Store(ctx context.Context, article model.Article) error
-	FindRecentUniqueTitles(ctx context.Context, title string, since time.Time) (bool, error)
+	Store(ctx context.Context, article model.Article) (model.StoreResult, error)
+	ArticleExists(ctx context.Context, link, canonicalLink string) (bool, error)
+	FindDuplicate(ctx context.Context, fp uint64, since time.Time, maxDistance int) (model.DuplicateMatch, bool, error)
+	RecordDuplicate(ctx context.Context, duplicate model.Duplicate) error
+}
+
+// LinkResolver finds the canonical link of an article. It returns a usable
+// link along with any error.
+type LinkResolver interface {
+	Resolve(ctx context.Context, link string) (string, error)
 }
 
 type SourceProvider interface {
 	Sources(ctx context.Context) ([]model.Source, error)
+	UpdateCacheValidators(ctx context.Context, sourceID int64, etag, lastModified string, hints model.FeedHints) error
+	SetNextFetchAt(ctx context.Context, sourceID int64, at time.Time) error
+	RecordFetchSuccess(ctx context.Context, sourceID int64, itemsCount, newItemsCount int) error
+	RecordFetchFailure(ctx context.Context, sourceID int64, fetchErr string) (int, error)
+	RecordThrottle(ctx context.Context, sourceID int64, until time.Time) error
+	Disable(ctx context.Context, sourceID int64, reason string) error
+	SetWebSubHub(ctx context.Context, sourceID int64, hub, topic string) error
+}
+
+// DropLog records the items the pipeline drops.
+type DropLog interface {
+	RecordDropped(ctx context.Context, item model.DroppedItem) error
+}
+
+type FilterRuleProvider interface {
+	FilterRules(ctx context.Context) ([]model.FilterRule, error)
 }
 
 type Source interface {
@@ -27,25 +58,90 @@ type Source interface {
 	Fetch(ctx context.Context) ([]model.Item, error)
 }
 
+// conditionalSource is implemented by sources that support conditional
+// requests and need their cache validators persisted between fetches.
+type conditionalSource interface {
+	CacheValidators() (etag string, lastModified string)
+}
+
 type Fetcher struct {
-	articles ArticleStorage
-	sources  SourceProvider
+	articles    ArticleStorage
+	sources     SourceProvider
+	filterRules FilterRuleProvider
+	drops       DropLog
+	links       LinkResolver
+	kinds       *sourcelib.Registry
 
 	fetchInterval  time.Duration
+	checkInterval  time.Duration
+	pipeline       Pipeline
 	filterKeywords []string
+	filterExpr     *filter.Expr
+	concurrency    int
+	limiter        *ratelimit.Limiter
+	schedule       *schedule
+	health         HealthPolicy
+	dedup          fingerprint.Policy
+}
+
+// Config holds the settings of the fetcher.
+type Config struct {
+	// FetchInterval is how often a source without an interval of its own
+	// is fetched.
+	FetchInterval time.Duration
+	// CheckInterval is how often due sources are looked for.
+	CheckInterval time.Duration
+	// PushPollInterval is how often sources a WebSub hub pushes to are
+	// still polled.
+	PushPollInterval time.Duration
+	// Concurrency is how many sources are fetched in parallel.
+	Concurrency    int
+	Pipeline       Pipeline
+	FilterKeywords []string
+	FilterExpr     *filter.Expr
+	// Limiter keeps requests to each host within its rate. It can be nil.
+	Limiter *ratelimit.Limiter
+	Health  HealthPolicy
+	Dedup   fingerprint.Policy
 }
 
-func New(articlesStorage ArticleStorage, sourcesProvider SourceProvider, fetchInterval time.Duration, filterKeywords []string) *Fetcher {
+func New(
+	articlesStorage ArticleStorage,
+	sourcesProvider SourceProvider,
+	filterRules FilterRuleProvider,
+	drops DropLog,
+	links LinkResolver,
+	kinds *sourcelib.Registry,
+	cfg Config,
+) *Fetcher {
+	concurrency := cfg.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
 	return &Fetcher{
 		articles:       articlesStorage,
 		sources:        sourcesProvider,
-		fetchInterval:  fetchInterval,
-		filterKeywords: filterKeywords,
+		filterRules:    filterRules,
+		drops:          drops,
+		links:          links,
+		kinds:          kinds,
+		fetchInterval:  cfg.FetchInterval,
+		checkInterval:  cfg.CheckInterval,
+		pipeline:       cfg.Pipeline,
+		filterKeywords: cfg.FilterKeywords,
+		filterExpr:     cfg.FilterExpr,
+		concurrency:    concurrency,
+		limiter:        cfg.Limiter,
+		schedule:       newSchedule(cfg.FetchInterval, cfg.PushPollInterval),
+		health:         cfg.Health,
+		dedup:          cfg.Dedup,
 	}
 }
 
+// Start checks for due sources every check interval and fetches them.
 func (f *Fetcher) Start(ctx context.Context) error {
-	ticker := time.NewTicker(f.fetchInterval)
+	ticker := time.NewTicker(f.checkInterval)
 	defer ticker.Stop()
 
 	if err := f.Fetch(ctx); err != nil {
@@ -70,27 +166,64 @@ func (f *Fetcher) Fetch(ctx context.Context) error {
 		return fmt.Errorf("failed to get sources: %w", err)
 	}
 
-	log.Printf("[INFO] Starting to fetch from %d sources", len(sources))
+	sources = interleaveByHost(dueSources(sources, time.Now()))
+	if len(sources) == 0 {
+		return nil
+	}
 
-	for i, source := range sources {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-			log.Printf("[INFO] Fetching source %d/%d: %s (priority: %d)",
-				i+1, len(sources), source.Name, source.Priority)
+	log.Printf("[INFO] Starting to fetch from %d due sources with %d workers", len(sources), f.concurrency)
+
+	var (
+		wg   sync.WaitGroup
+		jobs = make(chan model.Source)
+	)
+
+	for w := 0; w < f.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
 
-			if err := f.fetchSource(ctx, source); err != nil {
-				log.Printf("[ERROR] failed to fetch source %q: %v", source.Name, err)
-				continue
+			for source := range jobs {
+				// The token is taken before the fetch, so that waiting for
+				// it does not count against the fetch timeout.
+				reservedCtx, err := f.limiter.Acquire(ctx, ratelimit.HostOf(source.FeedURL))
+				if err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+
+					log.Printf("[INFO] Postponing source %q: %v", source.Name, err)
+					f.scheduleNext(ctx, source)
+					continue
+				}
+
+				log.Printf("[INFO] Fetching source %q (priority: %d)", source.Name, source.Priority)
+
+				if err := f.fetchSource(reservedCtx, source); err != nil {
+					log.Printf("[ERROR] failed to fetch source %q: %v", source.Name, err)
+				}
+
+				f.scheduleNext(ctx, source)
 			}
+		}()
+	}
 
-			waitTime := 30 * time.Second
-			log.Printf("[INFO] Waiting %v before fetching next source", waitTime)
-			time.Sleep(waitTime)
+dispatch:
+	for _, source := range sources {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- source:
 		}
 	}
 
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	log.Printf("[INFO] Completed fetching from all sources")
 	return nil
 }
@@ -99,64 +232,208 @@ func (f *Fetcher) fetchSource(ctx context.Context, sourceModel model.Source) err
 	fetchCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
 	defer cancel()
 
-	rssSource := sourcelib.NewRSSSourceFromModel(sourceModel)
+	src, err := f.kinds.New(sourceModel)
+	if err != nil {
+		f.recordFailure(ctx, sourceModel, err)
+		return fmt.Errorf("failed to create source %q: %w", sourceModel.Name, err)
+	}
 
-	items, err := rssSource.Fetch(fetchCtx)
+	items, err := src.Fetch(fetchCtx)
 	if err != nil {
-		return fmt.Errorf("failed to fetch items from source %q: %w", rssSource.Name(), err)
+		f.recordFailure(ctx, sourceModel, err)
+		return fmt.Errorf("failed to fetch items from source %q: %w", src.Name(), err)
 	}
 
-	log.Printf("[INFO] Fetched %d items from source %q", len(items), rssSource.Name())
+	log.Printf("[INFO] Fetched %d items from source %q", len(items), src.Name())
+
+	f.schedule.rememberHints(sourceModel.ID, src)
+	f.rememberHub(ctx, sourceModel, src)
 
-	if err := f.processItems(ctx, rssSource, items); err != nil {
-		return fmt.Errorf("failed to process items from source %q: %w", rssSource.Name(), err)
+	stored, err := f.processItems(ctx, sourceModel, items)
+	if err != nil {
+		f.recordFailure(ctx, sourceModel, err)
+		return fmt.Errorf("failed to process items from source %q: %w", src.Name(), err)
 	}
 
+	// The validators are saved only once the items are stored: saved before,
+	// a failed store would make the next fetch get 304 and lose the items.
+	f.saveCacheValidators(ctx, sourceModel, src)
+	f.recordSuccess(ctx, sourceModel, len(items), stored)
+
 	return nil
 }
 
-func (f *Fetcher) processItems(ctx context.Context, source Source, items []model.Item) error {
-	for _, item := range items {
-		item.Date = item.Date.UTC()
+// Ingest runs items pushed by a source through the pipeline and returns how
+// many were stored.
+func (f *Fetcher) Ingest(ctx context.Context, sourceModel model.Source, items []model.Item) (int, error) {
+	stored, err := f.processItems(ctx, sourceModel, items)
+	if err != nil {
+		return stored, fmt.Errorf("failed to process items from source %q: %w", sourceModel.Name, err)
+	}
 
-		if f.itemShouldBeSkipped(item) {
-			log.Printf("[INFO] item %q (%s) from source %q should be skipped", item.Title, item.Link, source.Name())
-			continue
-		}
+	f.recordSuccess(ctx, sourceModel, len(items), stored)
 
-		// Check article uniqueness
-		isUnique, err := f.articles.FindRecentUniqueTitles(ctx, item.Title, time.Now().AddDate(0, 0, -7))
-		if err != nil {
-			log.Printf("[WARN] Failed to check article uniqueness: %v", err)
-		} else if !isUnique {
-			log.Printf("[INFO] Skipping non-unique article: %s", item.Title)
+	return stored, nil
+}
+
+func (f *Fetcher) saveCacheValidators(ctx context.Context, sourceModel model.Source, source Source) {
+	conditional, ok := source.(conditionalSource)
+	if !ok {
+		return
+	}
+
+	etag, lastModified := conditional.CacheValidators()
+	hints := fetchedHints(sourceModel, source)
+	if etag == sourceModel.ETag && lastModified == sourceModel.LastModified &&
+		hints.Interval == sourceModel.FeedHints.Interval && slices.Equal(hints.SkipHours, sourceModel.FeedHints.SkipHours) {
+		return
+	}
+
+	if err := f.sources.UpdateCacheValidators(ctx, sourceModel.ID, etag, lastModified, hints); err != nil {
+		log.Printf("[WARN] failed to save cache validators for source %q: %v", sourceModel.Name, err)
+	}
+}
+
+// processItems runs the items through the pipeline, stores the ones that
+// pass it and returns how many were stored.
+func (f *Fetcher) processItems(ctx context.Context, source model.Source, items []model.Item) (int, error) {
+	stored := 0
+	stages := f.pipeline.build(ctx, f, source)
+
+	for _, item := range items {
+		if stage, drop := stages.run(ctx, &item); drop != nil {
+			f.recordDrop(ctx, source, item, stage, drop)
 			continue
 		}
 
 		article := model.Article{
-			SourceID:    source.ID(),
-			Title:       item.Title,
-			Link:        item.Link,
-			Summary:     item.Summary,
-			PublishedAt: item.Date,
+			SourceID:        source.ID,
+			Title:           item.Title,
+			Link:            item.Link,
+			CanonicalLink:   item.CanonicalLink,
+			CommentsLink:    item.CommentsLink,
+			GUID:            item.GUID,
+			Summary:         item.Summary,
+			Content:         item.Content,
+			ImageURL:        item.ImageURL,
+			Authors:         item.Authors,
+			PrimaryCategory: item.PrimaryCategory,
+			Categories:      item.Categories,
+			Fingerprint:     item.Fingerprint,
+			PublishedAt:     item.Date,
+			UpdatedAt:       item.Updated,
+		}
+
+		if extract.HasOwnText(article) {
+			article.Extraction = model.ExtractionDone
+		}
+
+		result, err := f.articles.Store(ctx, article)
+		if err != nil {
+			return stored, err
 		}
 
-		if err := f.articles.Store(ctx, article); err != nil {
-			return err
+		if result == model.ArticleInserted {
+			stored++
 		}
 	}
 
-	return nil
+	return stored, nil
+}
+
+// recordDrop logs an item a stage dropped. Quiet drops are not logged.
+func (f *Fetcher) recordDrop(ctx context.Context, source model.Source, item model.Item, stage string, drop *Drop) {
+	if drop.Quiet {
+		return
+	}
+
+	log.Printf("[INFO] item %q (%s) from source %q is dropped by stage %s: %s", item.Title, item.Link, source.Name, stage, drop.Reason)
+
+	if f.drops == nil {
+		return
+	}
+
+	if err := f.drops.RecordDropped(ctx, model.DroppedItem{
+		SourceID: source.ID,
+		Title:    item.Title,
+		Link:     item.Link,
+		Stage:    stage,
+		Reason:   drop.Reason,
+	}); err != nil {
+		log.Printf("[WARN] failed to record dropped item %s: %v", item.Link, err)
+	}
+}
+
+// findDuplicate returns the recent article the item repeats and records the
+// duplicate.
+func (f *Fetcher) findDuplicate(ctx context.Context, source model.Source, item model.Item) (model.DuplicateMatch, bool) {
+	match, found, err := f.articles.FindDuplicate(ctx, item.Fingerprint, time.Now().Add(-f.dedup.Lookback), f.dedup.MaxDistance)
+	if err != nil {
+		log.Printf("[WARN] failed to look for duplicates of %s: %v", item.Link, err)
+		return model.DuplicateMatch{}, false
+	}
+	if !found {
+		return model.DuplicateMatch{}, false
+	}
+
+	if err := f.articles.RecordDuplicate(ctx, model.Duplicate{
+		SourceID: source.ID,
+		Title:    item.Title,
+		Link:     item.Link,
+		Match:    match,
+	}); err != nil {
+		log.Printf("[WARN] failed to record duplicate %s: %v", item.Link, err)
+	}
+
+	return match, true
+}
+
+// canonicalLink returns the canonical link of an item and whether an
+// article with it is already stored.
+func (f *Fetcher) canonicalLink(ctx context.Context, link string) (string, bool) {
+	cleaned := canonical.Clean(link)
+
+	exists, err := f.articles.ArticleExists(ctx, link, cleaned)
+	if err != nil {
+		log.Printf("[WARN] failed to check whether %s is stored: %v", link, err)
+	} else if exists {
+		return cleaned, true
+	}
+
+	if f.links == nil {
+		return cleaned, false
+	}
+
+	resolved, err := f.links.Resolve(ctx, link)
+	if err != nil {
+		log.Printf("[WARN] failed to resolve canonical link of %s: %v", link, err)
+	}
+
+	if resolved == "" || resolved == cleaned {
+		return cleaned, false
+	}
+
+	exists, err = f.articles.ArticleExists(ctx, resolved, resolved)
+	if err != nil {
+		log.Printf("[WARN] failed to check whether %s is stored: %v", resolved, err)
+	} else if exists {
+		log.Printf("[INFO] Skipping %s, it is the already stored %s", link, resolved)
+		return resolved, true
+	}
+
+	return resolved, false
 }
 
-func (f *Fetcher) itemShouldBeSkipped(item model.Item) bool {
+// skippedKeyword returns the filter keyword that is one of the item's
+// categories or appears in its title.
+func (f *Fetcher) skippedKeyword(item model.Item) (string, bool) {
 	categoriesSet := set.New(item.Categories...)
 
 	for _, keyword := range f.filterKeywords {
 		if categoriesSet.Contains(keyword) || strings.Contains(strings.ToLower(item.Title), keyword) {
-			return true
+			return keyword, true
 		}
 	}
 
-	return false
+	return "", false
 }
@@ -0,0 +1,82 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+	sourcelib "neuro_scout_bot_v1/internal/source"
+)
+
+// memorySources records the cache validators, successes and failures saved
+// for sources. Other calls are not expected.
+type memorySources struct {
+	SourceProvider
+	validators map[int64]string
+	successes  int
+	failures   int
+}
+
+func (m *memorySources) UpdateCacheValidators(_ context.Context, sourceID int64, etag, _ string, _ model.FeedHints) error {
+	m.validators[sourceID] = etag
+	return nil
+}
+
+func (m *memorySources) RecordFetchSuccess(context.Context, int64, int, int) error {
+	m.successes++
+	return nil
+}
+
+func (m *memorySources) RecordFetchFailure(context.Context, int64, string) (int, error) {
+	m.failures++
+	return m.failures, nil
+}
+
+type conditionalStub struct {
+	items []model.Item
+	etag  string
+}
+
+func (s *conditionalStub) ID() int64    { return 1 }
+func (s *conditionalStub) Name() string { return "stub" }
+
+func (s *conditionalStub) Fetch(context.Context) ([]model.Item, error) {
+	return s.items, nil
+}
+
+func (s *conditionalStub) CacheValidators() (string, string) {
+	return s.etag, ""
+}
+
+func TestFetcher_fetchSourceCacheValidators(t *testing.T) {
+	pipeline, err := NewPipeline([]string{StageNormalize})
+	require.NoError(t, err)
+
+	stub := &conditionalStub{
+		items: []model.Item{{Title: "New model released", Link: "https://example.com/new"}},
+		etag:  `"v2"`,
+	}
+	kinds := sourcelib.NewRegistry(nil)
+	kinds.Register("stub", func(model.Source, *ratelimit.Limiter) (sourcelib.Source, error) { return stub, nil }, nil)
+
+	articles := &memoryArticles{storeErr: errors.New("database is down")}
+	sources := &memorySources{validators: make(map[int64]string)}
+	f := New(articles, sources, nil, &memoryDropLog{}, nil, kinds, Config{Pipeline: pipeline})
+
+	source := model.Source{ID: 1, Name: "Blog", Kind: "stub", ETag: `"v1"`}
+
+	require.Error(t, f.fetchSource(context.Background(), source))
+	assert.Empty(t, sources.validators, "validators are not saved when the items could not be stored")
+	assert.Zero(t, sources.successes)
+	assert.Equal(t, 1, sources.failures, "a failed store is a failed fetch")
+
+	articles.storeErr = nil
+	require.NoError(t, f.fetchSource(context.Background(), source))
+	assert.Equal(t, map[int64]string{1: `"v2"`}, sources.validators)
+	assert.Equal(t, 1, sources.successes)
+}
@@ -0,0 +1,51 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"neuro_scout_bot_v1/internal/filter"
+	"neuro_scout_bot_v1/internal/model"
+)
+
+// rulesFor loads the filter rules that apply to the source. When the rules
+// cannot be loaded, items are stored unfiltered rather than lost.
+func (f *Fetcher) rulesFor(ctx context.Context, sourceID int64) filter.Rules {
+	if f.filterRules == nil {
+		return filter.Rules{}
+	}
+
+	all, err := f.filterRules.FilterRules(ctx)
+	if err != nil {
+		log.Printf("[WARN] failed to load filter rules: %v", err)
+		return filter.Rules{}
+	}
+
+	rules, errs := filter.Compile(all, sourceID)
+	for _, err := range errs {
+		log.Printf("[WARN] skipping %v", err)
+	}
+
+	return rules
+}
+
+// sourceFilter parses the source's filter expression. Filters are checked
+// when they are set, so a broken one is logged and ignored.
+func sourceFilter(source model.Source) *filter.Expr {
+	expr, err := filter.ParseExpr(source.Filter)
+	if err != nil {
+		log.Printf("[WARN] ignoring invalid filter of source %q: %v", source.Name, err)
+		return nil
+	}
+
+	return expr
+}
+
+func describeRule(rule *model.FilterRule) string {
+	if rule == nil {
+		return "include rules"
+	}
+
+	return fmt.Sprintf("rule %d (%s %s %q)", rule.ID, rule.Action, rule.Match, rule.Pattern)
+}
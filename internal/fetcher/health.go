@@ -0,0 +1,72 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+// HealthPolicy decides when a source is disabled automatically. Zero values
+// turn the corresponding check off.
+type HealthPolicy struct {
+	// MaxFailures is the number of consecutive failed fetches after which
+	// the source is disabled.
+	MaxFailures int
+	// StaleAfter is how long a source may go without new items before it is
+	// disabled.
+	StaleAfter time.Duration
+}
+
+func (f *Fetcher) recordFailure(ctx context.Context, source model.Source, fetchErr error) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	if until, ok := ratelimit.ThrottledUntil(fetchErr); ok {
+		f.recordThrottle(ctx, source, until)
+		return
+	}
+
+	failures, err := f.sources.RecordFetchFailure(ctx, source.ID, fetchErr.Error())
+	if err != nil {
+		log.Printf("[WARN] failed to record fetch failure for source %q: %v", source.Name, err)
+		return
+	}
+
+	if f.health.MaxFailures > 0 && failures >= f.health.MaxFailures {
+		f.disable(ctx, source, fmt.Sprintf("%d consecutive fetch failures, last: %v", failures, fetchErr))
+	}
+}
+
+// recordThrottle notes that the source's host throttled the fetch, which is
+// not a failure.
+func (f *Fetcher) recordThrottle(ctx context.Context, source model.Source, until time.Time) {
+	log.Printf("[WARN] source %q is throttled until %s", source.Name, until.Format(time.RFC3339))
+
+	if err := f.sources.RecordThrottle(ctx, source.ID, until); err != nil {
+		log.Printf("[WARN] failed to record throttling of source %q: %v", source.Name, err)
+	}
+}
+
+func (f *Fetcher) recordSuccess(ctx context.Context, source model.Source, itemsCount, newItemsCount int) {
+	if err := f.sources.RecordFetchSuccess(ctx, source.ID, itemsCount, newItemsCount); err != nil {
+		log.Printf("[WARN] failed to record fetch success for source %q: %v", source.Name, err)
+		return
+	}
+
+	if newItemsCount == 0 && source.IsStale(f.health.StaleAfter, time.Now()) {
+		f.disable(ctx, source, fmt.Sprintf("no new items for more than %s", f.health.StaleAfter))
+	}
+}
+
+func (f *Fetcher) disable(ctx context.Context, source model.Source, reason string) {
+	log.Printf("[WARN] disabling source %q: %s", source.Name, reason)
+
+	if err := f.sources.Disable(ctx, source.ID, reason); err != nil {
+		log.Printf("[ERROR] failed to disable source %q: %v", source.Name, err)
+	}
+}
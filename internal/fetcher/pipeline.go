@@ -0,0 +1,202 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+
+	"neuro_scout_bot_v1/internal/filter"
+	"neuro_scout_bot_v1/internal/fingerprint"
+	"neuro_scout_bot_v1/internal/model"
+)
+
+// Built-in pipeline stages.
+const (
+	// StageNormalize converts item dates to UTC.
+	StageNormalize = "normalize"
+	// StageKeywords drops items whose title or categories contain a filter
+	// keyword.
+	StageKeywords = "keywords"
+	// StageRules applies the global and per-source filter rules.
+	StageRules = "rules"
+	// StageExpression applies the global and per-source filter expressions.
+	StageExpression = "expression"
+	// StageCanonical sets the canonical link and drops items already stored.
+	StageCanonical = "canonical"
+	// StageDedup sets the fingerprint and drops near-duplicates of stored
+	// articles.
+	StageDedup = "dedup"
+)
+
+// DefaultStages is the pipeline used when none is configured.
+var DefaultStages = []string{
+	StageNormalize,
+	StageKeywords,
+	StageRules,
+	StageExpression,
+	StageCanonical,
+	StageDedup,
+}
+
+// Drop says why a stage dropped an item. Quiet drops are not logged.
+type Drop struct {
+	Reason string
+	Quiet  bool
+}
+
+// Stage processes one item, or drops it by returning why.
+type Stage func(ctx context.Context, item *model.Item) *Drop
+
+// StageBuilder prepares a stage for a batch of items from one source.
+type StageBuilder func(ctx context.Context, f *Fetcher, source model.Source) Stage
+
+var builtinStages = map[string]StageBuilder{
+	StageNormalize:  normalizeStage,
+	StageKeywords:   keywordsStage,
+	StageRules:      rulesStage,
+	StageExpression: expressionStage,
+	StageCanonical:  canonicalStage,
+	StageDedup:      dedupStage,
+}
+
+// Pipeline is the ordered chain of stages items go through before they are
+// stored.
+type Pipeline struct {
+	names    []string
+	builders []StageBuilder
+}
+
+// NewPipeline returns the pipeline of the named stages, or of DefaultStages
+// when there are none.
+func NewPipeline(names []string) (Pipeline, error) {
+	if len(names) == 0 {
+		names = DefaultStages
+	}
+
+	var (
+		p    Pipeline
+		seen = make(map[string]bool, len(names))
+	)
+
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		builder, ok := builtinStages[name]
+		if !ok {
+			return Pipeline{}, fmt.Errorf("unknown pipeline stage %q, known stages: %s", name, strings.Join(DefaultStages, ", "))
+		}
+		if seen[name] {
+			return Pipeline{}, fmt.Errorf("pipeline stage %q is listed twice", name)
+		}
+
+		if name == StageDedup && !seen[StageCanonical] {
+			return Pipeline{}, fmt.Errorf("pipeline stage %q must come after %q", StageDedup, StageCanonical)
+		}
+
+		seen[name] = true
+		p.names = append(p.names, name)
+		p.builders = append(p.builders, builder)
+	}
+
+	return p, nil
+}
+
+// Stages returns the names of the pipeline's stages in order.
+func (p Pipeline) Stages() []string {
+	return append([]string(nil), p.names...)
+}
+
+type batchStages struct {
+	names  []string
+	stages []Stage
+}
+
+func (p Pipeline) build(ctx context.Context, f *Fetcher, source model.Source) batchStages {
+	b := batchStages{names: p.names, stages: make([]Stage, len(p.builders))}
+	for i, builder := range p.builders {
+		b.stages[i] = builder(ctx, f, source)
+	}
+
+	return b
+}
+
+// run passes the item through the stages until one drops it, and returns
+// the name of that stage with the reason.
+func (b batchStages) run(ctx context.Context, item *model.Item) (string, *Drop) {
+	for i, stage := range b.stages {
+		if drop := stage(ctx, item); drop != nil {
+			return b.names[i], drop
+		}
+	}
+
+	return "", nil
+}
+
+func normalizeStage(context.Context, *Fetcher, model.Source) Stage {
+	return func(_ context.Context, item *model.Item) *Drop {
+		item.Date = item.Date.UTC()
+		item.Updated = item.Updated.UTC()
+		return nil
+	}
+}
+
+func keywordsStage(_ context.Context, f *Fetcher, _ model.Source) Stage {
+	return func(_ context.Context, item *model.Item) *Drop {
+		if keyword, ok := f.skippedKeyword(*item); ok {
+			return &Drop{Reason: fmt.Sprintf("filter keyword %q", keyword)}
+		}
+		return nil
+	}
+}
+
+func rulesStage(ctx context.Context, f *Fetcher, source model.Source) Stage {
+	rules := f.rulesFor(ctx, source.ID)
+
+	return func(_ context.Context, item *model.Item) *Drop {
+		if keep, rule := rules.Check(*item); !keep {
+			return &Drop{Reason: "filtered out by " + describeRule(rule)}
+		}
+		return nil
+	}
+}
+
+func expressionStage(_ context.Context, f *Fetcher, source model.Source) Stage {
+	sourceExpr := sourceFilter(source)
+	now := time.Now()
+
+	return func(_ context.Context, item *model.Item) *Drop {
+		env := filter.Env{Item: *item, Source: source, Now: now}
+		if !f.filterExpr.Eval(env) {
+			return &Drop{Reason: "filtered out by the global filter"}
+		}
+		if !sourceExpr.Eval(env) {
+			return &Drop{Reason: "filtered out by the source filter"}
+		}
+		return nil
+	}
+}
+
+func canonicalStage(_ context.Context, f *Fetcher, _ model.Source) Stage {
+	return func(ctx context.Context, item *model.Item) *Drop {
+		canonicalLink, known := f.canonicalLink(ctx, item.Link)
+		item.CanonicalLink = canonicalLink
+		if known {
+			return &Drop{Reason: "already stored", Quiet: true}
+		}
+		return nil
+	}
+}
+
+func dedupStage(_ context.Context, f *Fetcher, source model.Source) Stage {
+	return func(ctx context.Context, item *model.Item) *Drop {
+		item.Fingerprint = fingerprint.Of(item.Title, lo.Ternary(item.Summary != "", item.Summary, item.Content))
+
+		if match, found := f.findDuplicate(ctx, source, *item); found {
+			return &Drop{Reason: fmt.Sprintf("duplicate of article %d %q (distance %d)", match.ArticleID, match.Title, match.Distance)}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,114 @@
+package fetcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/fingerprint"
+	"neuro_scout_bot_v1/internal/model"
+)
+
+type memoryArticles struct {
+	stored   []model.Article
+	links    map[string]bool
+	storeErr error
+}
+
+func (m *memoryArticles) Store(_ context.Context, article model.Article) (model.StoreResult, error) {
+	if m.storeErr != nil {
+		return model.ArticleUnchanged, m.storeErr
+	}
+
+	m.stored = append(m.stored, article)
+	return model.ArticleInserted, nil
+}
+
+func (m *memoryArticles) ArticleExists(_ context.Context, link, canonicalLink string) (bool, error) {
+	return m.links[link] || m.links[canonicalLink], nil
+}
+
+func (m *memoryArticles) FindDuplicate(context.Context, uint64, time.Time, int) (model.DuplicateMatch, bool, error) {
+	return model.DuplicateMatch{}, false, nil
+}
+
+func (m *memoryArticles) RecordDuplicate(context.Context, model.Duplicate) error {
+	return nil
+}
+
+type memoryDropLog struct {
+	dropped []model.DroppedItem
+}
+
+func (m *memoryDropLog) RecordDropped(_ context.Context, item model.DroppedItem) error {
+	m.dropped = append(m.dropped, item)
+	return nil
+}
+
+func TestNewPipeline(t *testing.T) {
+	pipeline, err := NewPipeline(nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultStages, pipeline.Stages())
+
+	pipeline, err = NewPipeline([]string{" Keywords", "normalize"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{StageKeywords, StageNormalize}, pipeline.Stages())
+
+	_, err = NewPipeline([]string{"normalize", "translate"})
+	assert.ErrorContains(t, err, `unknown pipeline stage "translate"`)
+
+	_, err = NewPipeline([]string{"rules", "rules"})
+	assert.Error(t, err)
+
+	_, err = NewPipeline([]string{"dedup", "canonical"})
+	assert.ErrorContains(t, err, `pipeline stage "dedup" must come after "canonical"`)
+
+	_, err = NewPipeline([]string{"normalize", "dedup"})
+	assert.Error(t, err, "dedup without canonical is rejected")
+}
+
+func TestFetcher_processItems(t *testing.T) {
+	pipeline, err := NewPipeline(nil)
+	require.NoError(t, err)
+
+	articles := &memoryArticles{links: map[string]bool{"https://example.com/old": true}}
+	drops := &memoryDropLog{}
+
+	f := New(articles, nil, nil, drops, nil, nil, Config{
+		FetchInterval:  time.Hour,
+		CheckInterval:  time.Minute,
+		Pipeline:       pipeline,
+		FilterKeywords: []string{"crypto"},
+		Dedup:          fingerprint.Policy{MaxDistance: 6, Lookback: time.Hour},
+	})
+
+	kyiv := time.FixedZone("EEST", 3*60*60)
+	items := []model.Item{
+		{Title: "New model released", Link: "https://example.com/new?utm_source=rss", CommentsLink: "https://news.ycombinator.com/item?id=1", Date: time.Date(2025, 6, 15, 12, 0, 0, 0, kyiv)},
+		{Title: "Crypto news", Link: "https://example.com/crypto"},
+		{Title: "Known article", Link: "https://example.com/old"},
+	}
+
+	stored, err := f.processItems(context.Background(), model.Source{ID: 3, Name: "Blog"}, items)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored)
+
+	require.Len(t, articles.stored, 1)
+	article := articles.stored[0]
+	assert.Equal(t, "https://example.com/new", article.CanonicalLink)
+	assert.Equal(t, "https://news.ycombinator.com/item?id=1", article.CommentsLink)
+	assert.Equal(t, time.UTC, article.PublishedAt.Location())
+	assert.Equal(t, time.Date(2025, 6, 15, 9, 0, 0, 0, time.UTC), article.PublishedAt)
+
+	require.Len(t, drops.dropped, 1, "already stored items are dropped quietly")
+	assert.Equal(t, model.DroppedItem{
+		SourceID: 3,
+		Title:    "Crypto news",
+		Link:     "https://example.com/crypto",
+		Stage:    StageKeywords,
+		Reason:   `filter keyword "crypto"`,
+	}, drops.dropped[0])
+}
@@ -0,0 +1,35 @@
+package fetcher
+
+import (
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+// interleaveByHost reorders sources so that consecutive entries belong to
+// different hosts where possible.
+func interleaveByHost(sources []model.Source) []model.Source {
+	var (
+		hosts   []string
+		byHost  = make(map[string][]model.Source)
+		ordered = make([]model.Source, 0, len(sources))
+	)
+
+	for _, source := range sources {
+		host := ratelimit.HostOf(source.FeedURL)
+		if _, ok := byHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], source)
+	}
+
+	for len(ordered) < len(sources) {
+		for _, host := range hosts {
+			if queue := byHost[host]; len(queue) > 0 {
+				ordered = append(ordered, queue[0])
+				byHost[host] = queue[1:]
+			}
+		}
+	}
+
+	return ordered
+}
@@ -0,0 +1,60 @@
+package fetcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+func TestHostLimit_Acquire(t *testing.T) {
+	limiter := ratelimit.NewLimiter(50*time.Millisecond, 1)
+	ctx := context.Background()
+
+	start := time.Now()
+	_, err := limiter.Acquire(ctx, ratelimit.HostOf("https://a.com/feed1"))
+	require.NoError(t, err)
+	_, err = limiter.Acquire(ctx, ratelimit.HostOf("https://b.com/feed"))
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 40*time.Millisecond, "different hosts should not wait")
+
+	_, err = limiter.Acquire(ctx, ratelimit.HostOf("https://A.com/feed2"))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, "same host should wait for the delay")
+}
+
+func TestHostLimit_AcquireCancelled(t *testing.T) {
+	limiter := ratelimit.NewLimiter(time.Hour, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := limiter.Acquire(ctx, "a.com")
+	require.NoError(t, err)
+
+	cancel()
+	_, err = limiter.Acquire(ctx, "a.com")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestInterleaveByHost(t *testing.T) {
+	sources := []model.Source{
+		{ID: 1, FeedURL: "https://a.com/feed1"},
+		{ID: 2, FeedURL: "https://a.com/feed2"},
+		{ID: 3, FeedURL: "https://A.com/feed3"},
+		{ID: 4, FeedURL: "https://b.com/feed"},
+		{ID: 5, FeedURL: "https://c.com/feed"},
+	}
+
+	ordered := interleaveByHost(sources)
+
+	ids := make([]int64, 0, len(ordered))
+	for _, source := range ordered {
+		ids = append(ids, source.ID)
+	}
+
+	assert.Equal(t, []int64{1, 4, 5, 2, 3}, ids)
+}
@@ -0,0 +1,148 @@
+package fetcher
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+	sourcelib "neuro_scout_bot_v1/internal/source"
+)
+
+// hintedSource is implemented by sources that read polling hints from the
+// feed itself.
+type hintedSource interface {
+	FetchHints() sourcelib.FeedHints
+}
+
+// schedule decides when each source is fetched next. Feed hints are also
+// stored with the cache validators, so they outlive a restart.
+type schedule struct {
+	defaultInterval time.Duration
+	// pushInterval is how often sources with an active WebSub subscription
+	// are still polled, in case the hub misses an update.
+	pushInterval time.Duration
+
+	mu    sync.Mutex
+	hints map[int64]sourcelib.FeedHints
+}
+
+func newSchedule(defaultInterval, pushInterval time.Duration) *schedule {
+	return &schedule{
+		defaultInterval: defaultInterval,
+		pushInterval:    pushInterval,
+		hints:           make(map[int64]sourcelib.FeedHints),
+	}
+}
+
+func (s *schedule) rememberHints(sourceID int64, source Source) {
+	hinted, ok := source.(hintedSource)
+	if !ok {
+		return
+	}
+
+	hints := hinted.FetchHints()
+	if hints.Interval() == 0 && len(hints.SkipHours) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hints[sourceID] = hints
+}
+
+// fetchedHints returns the hints the source read on this fetch, or the stored
+// ones when it read none, as when the feed was not modified.
+func fetchedHints(sourceModel model.Source, source Source) model.FeedHints {
+	hinted, ok := source.(hintedSource)
+	if !ok {
+		return sourceModel.FeedHints
+	}
+
+	hints := hinted.FetchHints()
+	if hints.Interval() == 0 && len(hints.SkipHours) == 0 {
+		return sourceModel.FeedHints
+	}
+
+	return model.FeedHints{Interval: hints.Interval(), SkipHours: hints.SkipHours}
+}
+
+// next returns the time of the next fetch, by the source's interval, the
+// feed's hints or the global interval, in that order.
+func (s *schedule) next(source model.Source, now time.Time) time.Time {
+	s.mu.Lock()
+	hints, ok := s.hints[source.ID]
+	s.mu.Unlock()
+
+	if !ok {
+		hints = sourcelib.FeedHints{TTL: source.FeedHints.Interval, SkipHours: source.FeedHints.SkipHours}
+	}
+
+	interval := source.FetchInterval
+	if interval <= 0 {
+		interval = hints.Interval()
+	}
+	if interval <= 0 {
+		interval = s.defaultInterval
+	}
+	if source.WebSub.Active(now) && interval < s.pushInterval {
+		interval = s.pushInterval
+	}
+
+	return hints.NextAllowed(now.Add(interval))
+}
+
+// rememberHub stores the WebSub hub advertised by the feed.
+func (f *Fetcher) rememberHub(ctx context.Context, sourceModel model.Source, source Source) {
+	hinted, ok := source.(hintedSource)
+	if !ok {
+		return
+	}
+
+	hints := hinted.FetchHints()
+	if hints.Hub == "" {
+		return
+	}
+
+	topic := hints.Self
+	if topic == "" {
+		topic = sourceModel.FeedURL
+	}
+
+	if hints.Hub == sourceModel.WebSub.Hub && topic == sourceModel.WebSub.Topic {
+		return
+	}
+
+	log.Printf("[INFO] source %q advertises WebSub hub %s for %s", sourceModel.Name, hints.Hub, topic)
+
+	if err := f.sources.SetWebSubHub(ctx, sourceModel.ID, hints.Hub, topic); err != nil {
+		log.Printf("[WARN] failed to save WebSub hub of source %q: %v", sourceModel.Name, err)
+	}
+}
+
+// scheduleNext schedules the next fetch, no earlier than the end of the
+// pause of a host that throttled requests.
+func (f *Fetcher) scheduleNext(ctx context.Context, source model.Source) {
+	next := f.schedule.next(source, time.Now())
+	if until := f.limiter.PausedUntil(ratelimit.HostOf(source.FeedURL)); until.After(next) {
+		next = until
+	}
+
+	if err := f.sources.SetNextFetchAt(ctx, source.ID, next); err != nil {
+		log.Printf("[WARN] failed to schedule next fetch for source %q: %v", source.Name, err)
+	}
+}
+
+func dueSources(sources []model.Source, now time.Time) []model.Source {
+	due := make([]model.Source, 0, len(sources))
+	for _, source := range sources {
+		if !source.Disabled && !source.IsPushed() && !source.NextFetchAt.After(now) {
+			due = append(due, source)
+		}
+	}
+
+	return due
+}
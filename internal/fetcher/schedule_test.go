@@ -0,0 +1,20 @@
+package fetcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+func TestSchedule_nextStoredHints(t *testing.T) {
+	s := newSchedule(15*time.Minute, time.Hour)
+	now := time.Date(2025, 6, 21, 10, 0, 0, 0, time.UTC)
+
+	source := model.Source{ID: 1, FeedHints: model.FeedHints{Interval: 2 * time.Hour, SkipHours: []int{12}}}
+
+	assert.Equal(t, now.Add(3*time.Hour), s.next(source, now), "stored hints apply before the feed is fetched again")
+	assert.Equal(t, now.Add(15*time.Minute), s.next(model.Source{ID: 2}, now))
+}
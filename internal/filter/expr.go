@@ -0,0 +1,579 @@
+package filter
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+// Filter expressions decide whether an item is kept, for example:
+//
+//	"LLM" AND NOT (title contains "crypto" OR text matches "job post(ing)?") AND source.priority >= 5
+//
+// A comparison is a field, an operator and a value. A lone string is short
+// for text contains "...". AND binds tighter than OR, NOT binds tightest;
+// keywords are case-insensitive.
+//
+// Fields:
+//
+//	title, summary, link    string
+//	text                    title, summary and categories
+//	category, author        list, true when any element matches
+//	link.domain             host of the link without "www."
+//	source.name             string
+//	source.priority         number
+//	age                     duration since the item was published, 0 when the item has no date
+//
+// String fields support contains, matches (a regex) and ==, != and compare
+// ignoring case. Numbers and durations (90m, 12h, 7d, 2w) support ==, !=, <,
+// <=, > and >=.
+
+// Env is what an expression is evaluated against.
+type Env struct {
+	Item   model.Item
+	Source model.Source
+	Now    time.Time
+}
+
+// Expr is a parsed filter expression. A nil Expr keeps every item.
+type Expr struct {
+	src  string
+	root node
+}
+
+// SyntaxError is a parse error at a position of the expression. Lines and
+// columns start at 1; columns count characters.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// ParseExpr parses a filter expression. An empty expression returns nil,
+// which keeps every item.
+func ParseExpr(src string) (*Expr, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, nil
+	}
+
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, tok.errorf("unexpected %s", tok)
+	}
+
+	return &Expr{src: src, root: root}, nil
+}
+
+// Eval reports whether the item is kept.
+func (e *Expr) Eval(env Env) bool {
+	if e == nil {
+		return true
+	}
+
+	return e.root.eval(env)
+}
+
+func (e *Expr) String() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.src
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokLParen
+	tokRParen
+	tokIdent
+	tokString
+	tokNumber
+	tokDuration
+	tokOperator
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	line   int
+	column int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+func (t token) errorf(format string, args ...any) error {
+	return &SyntaxError{Line: t.line, Column: t.column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// keyword reports whether the token is the given keyword, ignoring case.
+func (t token) keyword(word string) bool {
+	return t.kind == tokIdent && strings.EqualFold(t.text, word)
+}
+
+func lex(src string) ([]token, error) {
+	var (
+		tokens       []token
+		runes        = []rune(src)
+		line, column = 1, 1
+	)
+
+	advance := func(i int) int {
+		if runes[i] == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+		return i + 1
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := token{line: line, column: column}
+
+		switch {
+		case unicode.IsSpace(r):
+			i = advance(i)
+			continue
+
+		case r == '(' || r == ')':
+			start.kind, start.text = tokLParen, "("
+			if r == ')' {
+				start.kind, start.text = tokRParen, ")"
+			}
+			i = advance(i)
+
+		case r == '"' || r == '\'':
+			var sb strings.Builder
+			i = advance(i)
+			for {
+				if i >= len(runes) {
+					return nil, start.errorf("unterminated string")
+				}
+				if runes[i] == r {
+					i = advance(i)
+					break
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i = advance(i)
+				}
+				sb.WriteRune(runes[i])
+				i = advance(i)
+			}
+			start.kind, start.text = tokString, sb.String()
+
+		case strings.ContainsRune("=!<>", r):
+			op := string(r)
+			i = advance(i)
+			if i < len(runes) && runes[i] == '=' {
+				op += "="
+				i = advance(i)
+			}
+			if op == "=" || op == "!" {
+				return nil, start.errorf("unknown operator %q, did you mean %q?", op, op+"=")
+			}
+			start.kind, start.text = tokOperator, op
+
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.' || unicode.IsLetter(runes[j])) {
+				j++
+			}
+			start.text = string(runes[i:j])
+			start.kind = tokNumber
+			if strings.IndexFunc(start.text, unicode.IsLetter) >= 0 {
+				start.kind = tokDuration
+			}
+			for i < j {
+				i = advance(i)
+			}
+
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_' || runes[j] == '.') {
+				j++
+			}
+			start.kind, start.text = tokIdent, string(runes[i:j])
+			for i < j {
+				i = advance(i)
+			}
+
+		default:
+			return nil, start.errorf("unexpected character %q", r)
+		}
+
+		tokens = append(tokens, start)
+	}
+
+	return append(tokens, token{kind: tokEOF, line: line, column: column}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().keyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().keyword("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if !p.peek().keyword("not") {
+		return p.parsePrimary()
+	}
+
+	p.next()
+	operand, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	return notNode{operand}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+
+	switch {
+	case tok.kind == tokLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, closing.errorf("expected \")\" to close the \"(\" at line %d, column %d, found %s", tok.line, tok.column, closing)
+		}
+		return inner, nil
+
+	case tok.kind == tokString:
+		return &comparison{field: fields["text"], op: "contains", str: strings.ToLower(tok.text)}, nil
+
+	case tok.kind == tokIdent && !isKeyword(tok.text):
+		return p.parseComparison(tok)
+
+	default:
+		return nil, tok.errorf("expected a field, a string or \"(\", found %s", tok)
+	}
+}
+
+func isKeyword(word string) bool {
+	switch strings.ToLower(word) {
+	case "and", "or", "not", "contains", "matches":
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseComparison(fieldTok token) (node, error) {
+	f, ok := fields[strings.ToLower(fieldTok.text)]
+	if !ok {
+		return nil, fieldTok.errorf("unknown field %q, expected one of %s", fieldTok.text, fieldNames())
+	}
+
+	opTok := p.next()
+	op := strings.ToLower(opTok.text)
+	switch {
+	case opTok.kind == tokIdent && (op == "contains" || op == "matches"):
+		if f.kind != kindString {
+			return nil, opTok.errorf("%s cannot be used with %s, which is a %s", op, fieldTok.text, f.kind)
+		}
+	case opTok.kind == tokOperator:
+		if f.kind == kindString && op != "==" && op != "!=" {
+			return nil, opTok.errorf("%s cannot be used with %s, which is a %s", op, fieldTok.text, f.kind)
+		}
+	default:
+		return nil, opTok.errorf("expected an operator after %s, found %s", fieldTok.text, opTok)
+	}
+
+	valueTok := p.next()
+	c := &comparison{field: f, op: op}
+
+	switch f.kind {
+	case kindString:
+		if valueTok.kind != tokString {
+			return nil, valueTok.errorf("expected a string to compare %s with, found %s", fieldTok.text, valueTok)
+		}
+		c.str = strings.ToLower(valueTok.text)
+		if op == "matches" {
+			re, err := regexp.Compile("(?i)" + valueTok.text)
+			if err != nil {
+				return nil, valueTok.errorf("invalid regex: %v", err)
+			}
+			c.re = re
+		}
+
+	case kindNumber:
+		if valueTok.kind != tokNumber {
+			return nil, valueTok.errorf("expected a number to compare %s with, found %s", fieldTok.text, valueTok)
+		}
+		n, err := strconv.ParseFloat(valueTok.text, 64)
+		if err != nil {
+			return nil, valueTok.errorf("invalid number %q", valueTok.text)
+		}
+		c.num = n
+
+	case kindDuration:
+		if valueTok.kind != tokDuration {
+			return nil, valueTok.errorf("expected a duration such as 12h or 7d to compare %s with, found %s", fieldTok.text, valueTok)
+		}
+		d, err := parseDuration(valueTok.text)
+		if err != nil {
+			return nil, valueTok.errorf("%v", err)
+		}
+		c.num = float64(d)
+	}
+
+	return c, nil
+}
+
+var durationPart = regexp.MustCompile(`^(\d+(?:\.\d+)?)(ms|s|m|h|d|w)`)
+
+// parseDuration extends time.ParseDuration with days and weeks.
+func parseDuration(s string) (time.Duration, error) {
+	var total time.Duration
+
+	for rest := s; rest != ""; {
+		m := durationPart.FindStringSubmatch(rest)
+		if m == nil {
+			return 0, fmt.Errorf("invalid duration %q, expected a number followed by ms, s, m, h, d or w", s)
+		}
+
+		n, _ := strconv.ParseFloat(m[1], 64)
+		switch m[2] {
+		case "d":
+			total += time.Duration(n * float64(24*time.Hour))
+		case "w":
+			total += time.Duration(n * float64(7*24*time.Hour))
+		default:
+			d, err := time.ParseDuration(m[0])
+			if err != nil {
+				return 0, err
+			}
+			total += d
+		}
+
+		rest = rest[len(m[0]):]
+	}
+
+	return total, nil
+}
+
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindNumber
+	kindDuration
+)
+
+func (k valueKind) String() string {
+	switch k {
+	case kindNumber:
+		return "number"
+	case kindDuration:
+		return "duration"
+	default:
+		return "string"
+	}
+}
+
+type field struct {
+	kind    valueKind
+	strings func(env Env) []string
+	number  func(env Env) float64
+}
+
+var fields = map[string]field{
+	"title":   stringField(func(env Env) []string { return []string{env.Item.Title} }),
+	"summary": stringField(func(env Env) []string { return []string{env.Item.Summary} }),
+	"link":    stringField(func(env Env) []string { return []string{env.Item.Link} }),
+	"text":    stringField(func(env Env) []string { return itemTexts(env.Item) }),
+	"category": stringField(func(env Env) []string {
+		return env.Item.Categories
+	}),
+	"author": stringField(func(env Env) []string {
+		return env.Item.Authors
+	}),
+	"link.domain": stringField(func(env Env) []string {
+		return []string{linkDomain(env.Item.Link)}
+	}),
+	"source.name": stringField(func(env Env) []string { return []string{env.Source.Name} }),
+	"source.priority": {
+		kind:   kindNumber,
+		number: func(env Env) float64 { return float64(env.Source.Priority) },
+	},
+	"age": {
+		kind: kindDuration,
+		number: func(env Env) float64 {
+			if env.Item.Date.IsZero() {
+				return 0
+			}
+			return float64(env.Now.Sub(env.Item.Date))
+		},
+	},
+}
+
+func stringField(values func(env Env) []string) field {
+	return field{kind: kindString, strings: values}
+}
+
+func fieldNames() string {
+	return "title, summary, text, category, author, link, link.domain, source.name, source.priority, age"
+}
+
+func linkDomain(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+type node interface {
+	eval(env Env) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(env Env) bool { return n.left.eval(env) && n.right.eval(env) }
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(env Env) bool { return n.left.eval(env) || n.right.eval(env) }
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(env Env) bool { return !n.operand.eval(env) }
+
+type comparison struct {
+	field field
+	op    string
+	str   string
+	num   float64
+	re    *regexp.Regexp
+}
+
+func (c *comparison) eval(env Env) bool {
+	if c.field.kind != kindString {
+		return compareNumbers(c.field.number(env), c.op, c.num)
+	}
+
+	values := c.field.strings(env)
+	if c.op == "!=" {
+		return !anyString(values, func(v string) bool { return strings.EqualFold(strings.TrimSpace(v), c.str) })
+	}
+
+	return anyString(values, func(v string) bool {
+		switch c.op {
+		case "contains":
+			return strings.Contains(strings.ToLower(v), c.str)
+		case "matches":
+			return c.re.MatchString(v)
+		default:
+			return strings.EqualFold(strings.TrimSpace(v), c.str)
+		}
+	})
+}
+
+func anyString(values []string, pred func(string) bool) bool {
+	for _, v := range values {
+		if pred(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func compareNumbers(a float64, op string, b float64) bool {
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
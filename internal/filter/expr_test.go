@@ -0,0 +1,108 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+func TestParseExpr_Eval(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	env := Env{
+		Item: model.Item{
+			Title:      "Open-weight LLMs are catching up",
+			Summary:    "A look at benchmark results.",
+			Categories: []string{"AI", "Open Source"},
+			Authors:    []string{"Jane Doe"},
+			Link:       "https://www.example.com/posts/llms",
+			Date:       now.Add(-3 * time.Hour),
+		},
+		Source: model.Source{Name: "Example Blog", Priority: 6},
+		Now:    now,
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`"llm"`, true},
+		{`"LLM" AND NOT ("crypto" OR "job posting") AND source.priority >= 5`, true},
+		{`"LLM" and source.priority > 6`, false},
+		{`title contains "open-weight"`, true},
+		{`summary contains "llm"`, false},
+		{`text contains "open source"`, true},
+		{`title matches "^open-\\w+ llms?"`, true},
+		{`category == "ai"`, true},
+		{`category != "ai"`, false},
+		{`category != "crypto"`, true},
+		{`author == "jane doe"`, true},
+		{`link.domain == "example.com"`, true},
+		{`link contains "/posts/"`, true},
+		{`source.name == 'example blog'`, true},
+		{`age < 6h`, true},
+		{`age > 1d`, false},
+		{`age <= 1h30m`, false},
+		{`not not "llm"`, true},
+		{`"crypto" or "benchmark" and source.priority == 6`, true},
+		{`("crypto" or "benchmark") and source.priority != 6`, false},
+		{"\"llm\"\nAND age < 1w", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := ParseExpr(tt.expr)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, expr.Eval(env))
+		})
+	}
+}
+
+func TestParseExpr_Empty(t *testing.T) {
+	expr, err := ParseExpr("  \n ")
+	require.NoError(t, err)
+	assert.Nil(t, expr)
+	assert.True(t, expr.Eval(Env{}))
+}
+
+func TestParseExpr_Errors(t *testing.T) {
+	tests := []struct {
+		expr   string
+		line   int
+		column int
+		msg    string
+	}{
+		{`title contains`, 1, 15, "expected a string"},
+		{`titel contains "x"`, 1, 1, `unknown field "titel"`},
+		{`"a" AND ("b" OR "c"`, 1, 20, `expected ")"`},
+		{`"a" "b"`, 1, 5, `unexpected "b"`},
+		{`title contains "x`, 1, 16, "unterminated string"},
+		{`source.priority contains "5"`, 1, 17, "contains cannot be used with source.priority"},
+		{`title > "x"`, 1, 7, "> cannot be used with title"},
+		{`source.priority >= "high"`, 1, 20, "expected a number"},
+		{"\"llm\" AND\n  age < 3", 2, 9, "expected a duration"},
+		{"\"llm\"\nAND age < 3x", 2, 11, "invalid duration"},
+		{`title matches "(gpt"`, 1, 15, "invalid regex"},
+		{`title = "x"`, 1, 7, `did you mean "=="`},
+		{`"x" AND`, 1, 8, "found end of expression"},
+		{`title # "x"`, 1, 7, "unexpected character"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseExpr(tt.expr)
+			require.Error(t, err)
+
+			var syntaxErr *SyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "%T is not a syntax error", err)
+			assert.Equal(t, tt.line, syntaxErr.Line)
+			assert.Equal(t, tt.column, syntaxErr.Column)
+			assert.Contains(t, syntaxErr.Msg, tt.msg)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Package filter decides which fetched items are stored.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+// Matcher is a compiled filter rule.
+type Matcher struct {
+	Rule model.FilterRule
+	re   *regexp.Regexp
+}
+
+// NewMatcher checks the rule and compiles its pattern. Regexes are matched
+// case-insensitively.
+func NewMatcher(rule model.FilterRule) (*Matcher, error) {
+	switch rule.Action {
+	case model.FilterActionInclude, model.FilterActionExclude:
+	default:
+		return nil, fmt.Errorf("unknown action %q, expected include or exclude", rule.Action)
+	}
+
+	if strings.TrimSpace(rule.Pattern) == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+
+	m := &Matcher{Rule: rule}
+
+	switch rule.Match {
+	case model.FilterMatchKeyword, model.FilterMatchCategory, model.FilterMatchAuthor:
+	case model.FilterMatchRegex:
+		re, err := regexp.Compile("(?i)" + rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		m.re = re
+	default:
+		return nil, fmt.Errorf("unknown match %q, expected keyword, regex, category or author", rule.Match)
+	}
+
+	return m, nil
+}
+
+// Match reports whether the rule's pattern matches the item.
+func (m *Matcher) Match(item model.Item) bool {
+	switch m.Rule.Match {
+	case model.FilterMatchKeyword:
+		keyword := strings.ToLower(m.Rule.Pattern)
+		for _, text := range itemTexts(item) {
+			if strings.Contains(strings.ToLower(text), keyword) {
+				return true
+			}
+		}
+	case model.FilterMatchRegex:
+		for _, text := range itemTexts(item) {
+			if m.re.MatchString(text) {
+				return true
+			}
+		}
+	case model.FilterMatchCategory:
+		return containsFold(item.Categories, m.Rule.Pattern)
+	case model.FilterMatchAuthor:
+		return containsFold(item.Authors, m.Rule.Pattern)
+	}
+
+	return false
+}
+
+func itemTexts(item model.Item) []string {
+	texts := make([]string, 0, len(item.Categories)+2)
+	texts = append(texts, item.Title, item.Summary)
+	return append(texts, item.Categories...)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(value)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Rules are the compiled rules that apply to one source.
+type Rules struct {
+	global []*Matcher
+	source []*Matcher
+}
+
+// Compile picks the global rules and the rules of the source. Rules that do
+// not compile are returned as errors and left out.
+func Compile(rules []model.FilterRule, sourceID int64) (Rules, []error) {
+	var (
+		compiled Rules
+		errs     []error
+	)
+
+	for _, rule := range rules {
+		if rule.SourceID != 0 && rule.SourceID != sourceID {
+			continue
+		}
+
+		m, err := NewMatcher(rule)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("filter rule %d: %w", rule.ID, err))
+			continue
+		}
+
+		if rule.SourceID == 0 {
+			compiled.global = append(compiled.global, m)
+		} else {
+			compiled.source = append(compiled.source, m)
+		}
+	}
+
+	return compiled, errs
+}
+
+// Check reports whether the item passes the global and then the source's
+// rules. It returns the exclude rule that dropped the item, if any.
+func (r Rules) Check(item model.Item) (bool, *model.FilterRule) {
+	for _, matchers := range [][]*Matcher{r.global, r.source} {
+		if keep, rule := check(matchers, item); !keep {
+			return false, rule
+		}
+	}
+
+	return true, nil
+}
+
+func check(matchers []*Matcher, item model.Item) (bool, *model.FilterRule) {
+	hasInclude, included := false, false
+
+	for _, m := range matchers {
+		switch m.Rule.Action {
+		case model.FilterActionExclude:
+			if m.Match(item) {
+				return false, &m.Rule
+			}
+		case model.FilterActionInclude:
+			hasInclude = true
+			if !included && m.Match(item) {
+				included = true
+			}
+		}
+	}
+
+	return !hasInclude || included, nil
+}
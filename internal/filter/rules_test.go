@@ -0,0 +1,101 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+func TestNewMatcher_Invalid(t *testing.T) {
+	tests := map[string]model.FilterRule{
+		"unknown action": {Action: "drop", Match: model.FilterMatchKeyword, Pattern: "x"},
+		"unknown match":  {Action: model.FilterActionExclude, Match: "title", Pattern: "x"},
+		"empty pattern":  {Action: model.FilterActionExclude, Match: model.FilterMatchKeyword, Pattern: " "},
+		"bad regex":      {Action: model.FilterActionExclude, Match: model.FilterMatchRegex, Pattern: "(gpt"},
+	}
+
+	for name, rule := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewMatcher(rule)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMatcher_Match(t *testing.T) {
+	item := model.Item{
+		Title:      "Scaling laws for sparse LLMs",
+		Summary:    "We study mixture-of-experts models.",
+		Categories: []string{"cs.LG", "Machine Learning"},
+		Authors:    []string{"Ada Lovelace"},
+	}
+
+	tests := []struct {
+		match   string
+		pattern string
+		want    bool
+	}{
+		{model.FilterMatchKeyword, "llm", true},
+		{model.FilterMatchKeyword, "mixture-of-experts", true},
+		{model.FilterMatchKeyword, "machine learning", true},
+		{model.FilterMatchKeyword, "crypto", false},
+		{model.FilterMatchRegex, `\bllms?\b`, true},
+		{model.FilterMatchRegex, `^sparse`, false},
+		{model.FilterMatchCategory, "CS.LG", true},
+		{model.FilterMatchCategory, "cs", false},
+		{model.FilterMatchAuthor, "ada lovelace", true},
+		{model.FilterMatchAuthor, "Ada", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.match+" "+tt.pattern, func(t *testing.T) {
+			m, err := NewMatcher(model.FilterRule{Action: model.FilterActionInclude, Match: tt.match, Pattern: tt.pattern})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, m.Match(item))
+		})
+	}
+}
+
+func TestRules_Check(t *testing.T) {
+	rules := []model.FilterRule{
+		{ID: 1, Action: model.FilterActionExclude, Match: model.FilterMatchKeyword, Pattern: "crypto"},
+		{ID: 2, SourceID: 7, Action: model.FilterActionInclude, Match: model.FilterMatchCategory, Pattern: "cs.LG"},
+		{ID: 3, SourceID: 7, Action: model.FilterActionInclude, Match: model.FilterMatchCategory, Pattern: "cs.CL"},
+		{ID: 4, SourceID: 8, Action: model.FilterActionExclude, Match: model.FilterMatchKeyword, Pattern: "llm"},
+		{ID: 5, SourceID: 7, Action: model.FilterActionExclude, Match: model.FilterMatchRegex, Pattern: "("},
+	}
+
+	compiled, errs := Compile(rules, 7)
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "filter rule 5")
+
+	t.Run("global exclude", func(t *testing.T) {
+		keep, rule := compiled.Check(model.Item{Title: "Crypto LLMs", Categories: []string{"cs.LG"}})
+		assert.False(t, keep)
+		require.NotNil(t, rule)
+		assert.Equal(t, int64(1), rule.ID)
+	})
+
+	t.Run("matches an include rule", func(t *testing.T) {
+		keep, _ := compiled.Check(model.Item{Title: "LLMs", Categories: []string{"cs.CL"}})
+		assert.True(t, keep)
+	})
+
+	t.Run("matches no include rule", func(t *testing.T) {
+		keep, rule := compiled.Check(model.Item{Title: "Robots", Categories: []string{"cs.RO"}})
+		assert.False(t, keep)
+		assert.Nil(t, rule)
+	})
+
+	t.Run("rules of other sources are ignored", func(t *testing.T) {
+		other, errs := Compile(rules, 9)
+		assert.Empty(t, errs)
+
+		keep, _ := other.Check(model.Item{Title: "LLMs", Categories: []string{"cs.RO"}})
+		assert.True(t, keep)
+	})
+}
@@ -0,0 +1,12 @@
+package fingerprint
+
+import "time"
+
+// Policy says when an article counts as a near-duplicate of an earlier one.
+type Policy struct {
+	// MaxDistance is the largest Hamming distance between fingerprints of
+	// duplicates.
+	MaxDistance int
+	// Lookback is how far back earlier articles are compared.
+	Lookback time.Duration
+}
@@ -0,0 +1,97 @@
+// Package fingerprint computes SimHash fingerprints of article text, so
+// near-duplicates are found by Hamming distance.
+package fingerprint
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MinTokens is the number of words below which no fingerprint is computed:
+// the fingerprints of a few words collide too easily.
+const MinTokens = 8
+
+// maxTokens bounds the text that is fingerprinted, so long bodies do not
+// outweigh the title.
+const maxTokens = 1000
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// Of returns the fingerprint of an article's title and body, or 0 when the
+// text is too short.
+func Of(title, body string) uint64 {
+	titleTokens := tokens(title)
+	bodyTokens := tokens(htmlTag.ReplaceAllString(body, " "))
+
+	if len(titleTokens)+len(bodyTokens) < MinTokens {
+		return 0
+	}
+
+	if len(bodyTokens) > maxTokens {
+		bodyTokens = bodyTokens[:maxTokens]
+	}
+
+	var weights [64]int
+	for _, words := range [][]string{titleTokens, bodyTokens} {
+		for _, word := range words {
+			h := hash(word)
+			for bit := 0; bit < 64; bit++ {
+				if h&(1<<bit) != 0 {
+					weights[bit]++
+				} else {
+					weights[bit]--
+				}
+			}
+		}
+	}
+
+	var fp uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fp |= 1 << bit
+		}
+	}
+
+	return fp
+}
+
+// Bands splits a fingerprint into numbered 8-bit bands. Fingerprints within
+// len(Bands)-1 bits share a band.
+func Bands(fp uint64) []int32 {
+	bands := make([]int32, 8)
+	for i := range bands {
+		bands[i] = int32(i<<8) | int32((fp>>(8*i))&0xff)
+	}
+
+	return bands
+}
+
+// Distance is the number of bits two fingerprints differ in.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+func tokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// hash is FNV-1a followed by the MurmurHash3 finalizer, which spreads
+// small differences in the input over all bits.
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+
+	return x
+}
@@ -0,0 +1,64 @@
+package fingerprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const story = `<p>OpenAI has released a new family of open-weight language models that
+can run on a single GPU. The models come in three sizes and are licensed under
+Apache 2.0. Early benchmarks show the largest model matching proprietary systems
+on reasoning tasks, while the smallest one fits on a laptop. The company says the
+release is meant to help researchers study model safety and to give developers a
+capable base for fine-tuning on their own data.</p>`
+
+func TestOf(t *testing.T) {
+	original := Of("OpenAI releases open-weight models", story)
+	assert.NotZero(t, original)
+
+	t.Run("same text", func(t *testing.T) {
+		assert.Equal(t, original, Of("OpenAI Releases Open-Weight Models!", story))
+	})
+
+	t.Run("rewritten headline and light edits", func(t *testing.T) {
+		rewrite := Of("OpenAI's new open models run on one GPU",
+			story+" The weights are available on Hugging Face.")
+		assert.LessOrEqual(t, Distance(original, rewrite), 6)
+	})
+
+	t.Run("different story", func(t *testing.T) {
+		other := Of("Rust 1.80 stabilizes lazy cells",
+			`The Rust team has published version 1.80 of the compiler. The release
+			stabilizes LazyCell and LazyLock, adds exclusive ranges in patterns and
+			checks cfg names and values at compile time.`)
+		assert.Greater(t, Distance(original, other), 20)
+	})
+
+	t.Run("too short", func(t *testing.T) {
+		assert.Zero(t, Of("GPT-5 is out", ""))
+	})
+}
+
+func TestBands(t *testing.T) {
+	a := uint64(0x0102030405060708)
+	b := a ^ 0x0000ff000000ff00 // two bands differ
+
+	bandsA, bandsB := Bands(a), Bands(b)
+	assert.Equal(t, int32(0x08), bandsA[0])
+	assert.Equal(t, int32(7<<8|0x01), bandsA[7])
+
+	shared := 0
+	for i := range bandsA {
+		if bandsA[i] == bandsB[i] {
+			shared++
+		}
+	}
+	assert.Equal(t, 6, shared)
+}
+
+func TestDistance(t *testing.T) {
+	assert.Equal(t, 0, Distance(0xff, 0xff))
+	assert.Equal(t, 2, Distance(0b1010, 0b0110))
+	assert.Equal(t, 64, Distance(0, ^uint64(0)))
+}
@@ -0,0 +1,190 @@
+// Package httpclient builds the HTTP clients of sources from their options.
+package httpclient
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+// DefaultTimeout bounds a request when the options set no timeout.
+const DefaultTimeout = 30 * time.Second
+
+// UserAgent is sent with requests, as some sites refuse clients that do not
+// look like a browser. Source options can override it.
+const UserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.114 Safari/537.36"
+
+// New returns a client that makes requests with the options, through the
+// limiter. Secrets are only sent to the given hosts.
+func New(opts model.HTTPOptions, limiter *ratelimit.Limiter, hosts ...string) *http.Client {
+	var base http.RoundTripper = http.DefaultTransport
+
+	if opts.ProxyURL != "" || opts.InsecureSkipVerify {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+
+		if opts.ProxyURL != "" {
+			// An invalid proxy fails every request rather than being
+			// silently bypassed.
+			proxyURL, err := parseProxyURL(opts.ProxyURL)
+			transport.Proxy = func(*http.Request) (*url.URL, error) {
+				return proxyURL, err
+			}
+		}
+
+		if opts.InsecureSkipVerify {
+			transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		}
+
+		base = transport
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	allowed := normalizeHosts(hosts)
+
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &optionsTransport{
+			base:  ratelimit.NewTransport(base, limiter),
+			opts:  opts,
+			hosts: allowed,
+		},
+	}
+
+	if len(opts.Cookies) > 0 {
+		jar, _ := cookiejar.New(nil)
+		for host := range allowed {
+			jar.SetCookies(&url.URL{Scheme: "https", Host: host}, cookies(opts.Cookies))
+		}
+		client.Jar = jar
+	}
+
+	return client
+}
+
+// Validate checks options before they are saved.
+func Validate(opts model.HTTPOptions) error {
+	for name, value := range opts.Headers {
+		if !validHeaderName(name) || strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("invalid header %q", name)
+		}
+	}
+
+	for name, value := range opts.Cookies {
+		if (&http.Cookie{Name: name, Value: value}).Valid() != nil {
+			return fmt.Errorf("invalid cookie %q", name)
+		}
+	}
+
+	if opts.BearerToken != "" && (opts.Username != "" || opts.Password != "") {
+		return errors.New("use either basic auth or a bearer token, not both")
+	}
+
+	if opts.ProxyURL != "" {
+		if _, err := parseProxyURL(opts.ProxyURL); err != nil {
+			return err
+		}
+	}
+
+	if opts.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+
+	return nil
+}
+
+// validHeaderName reports whether name is an RFC 7230 token.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, r := range name {
+		if r > 0x7e || r <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseProxyURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q: scheme must be http, https or socks5", rawURL)
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: no host", rawURL)
+	}
+
+	return u, nil
+}
+
+// optionsTransport adds the user agent, headers and credentials of the
+// options to requests.
+type optionsTransport struct {
+	base  http.RoundTripper
+	opts  model.HTTPOptions
+	hosts map[string]bool
+}
+
+func (t *optionsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+
+	if t.opts.UserAgent != "" {
+		req.Header.Set("User-Agent", t.opts.UserAgent)
+	}
+
+	if t.hosts[strings.ToLower(req.URL.Hostname())] {
+		for name, value := range t.opts.Headers {
+			req.Header.Set(name, value)
+		}
+
+		switch {
+		case t.opts.BearerToken != "":
+			req.Header.Set("Authorization", "Bearer "+t.opts.BearerToken)
+		case t.opts.Username != "" || t.opts.Password != "":
+			req.SetBasicAuth(t.opts.Username, t.opts.Password)
+		}
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+func normalizeHosts(hosts []string) map[string]bool {
+	normalized := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		if host != "" {
+			normalized[strings.ToLower(host)] = true
+		}
+	}
+
+	return normalized
+}
+
+func cookies(values map[string]string) []*http.Cookie {
+	result := make([]*http.Cookie, 0, len(values))
+	for name, value := range values {
+		result = append(result, &http.Cookie{Name: name, Value: value, Path: "/"})
+	}
+
+	return result
+}
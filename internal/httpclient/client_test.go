@@ -0,0 +1,81 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+func TestNew(t *testing.T) {
+	requests := make(chan *http.Request, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r
+	}))
+	defer server.Close()
+
+	opts := model.HTTPOptions{
+		UserAgent:   "NeuroScout/1.0",
+		Headers:     map[string]string{"X-Api-Key": "key"},
+		BearerToken: "token",
+		Cookies:     map[string]string{"session": "abc"},
+		Timeout:     5 * time.Second,
+	}
+
+	client := New(opts, nil, ratelimit.HostOf(server.URL))
+	assert.Equal(t, 5*time.Second, client.Timeout)
+
+	resp, err := client.Get(server.URL + "/feed")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	r := <-requests
+	assert.Equal(t, "NeuroScout/1.0", r.Header.Get("User-Agent"))
+	assert.Equal(t, "key", r.Header.Get("X-Api-Key"))
+	assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+	cookie, err := r.Cookie("session")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", cookie.Value)
+
+	// Secrets are kept from hosts the options are not for.
+	client = New(opts, nil, "feeds.example.com")
+
+	resp, err = client.Get(server.URL + "/feed")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	r = <-requests
+	assert.Equal(t, "NeuroScout/1.0", r.Header.Get("User-Agent"))
+	assert.Empty(t, r.Header.Get("X-Api-Key"))
+	assert.Empty(t, r.Header.Get("Authorization"))
+	assert.Empty(t, r.Cookies())
+}
+
+func TestNew_InvalidProxy(t *testing.T) {
+	client := New(model.HTTPOptions{ProxyURL: "ftp://proxy"}, nil)
+
+	_, err := client.Get("http://example.com")
+	assert.ErrorContains(t, err, "invalid proxy URL")
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(model.HTTPOptions{}))
+	assert.NoError(t, Validate(model.HTTPOptions{
+		Headers:  map[string]string{"X-Api-Key": "key"},
+		Username: "user",
+		Password: "secret",
+		ProxyURL: "socks5://proxy.internal:1080",
+	}))
+
+	assert.Error(t, Validate(model.HTTPOptions{Headers: map[string]string{"Bad Header": "x"}}))
+	assert.Error(t, Validate(model.HTTPOptions{Headers: map[string]string{"X-Injected": "a\r\nb"}}))
+	assert.Error(t, Validate(model.HTTPOptions{Cookies: map[string]string{"session": "a;b"}}))
+	assert.Error(t, Validate(model.HTTPOptions{Username: "user", BearerToken: "token"}))
+	assert.Error(t, Validate(model.HTTPOptions{ProxyURL: "proxy.internal:3128"}))
+}
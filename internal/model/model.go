@@ -1,31 +1,280 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
+
+// Source kinds. Sources created before kinds existed are RSS sources.
+const (
+	SourceKindRSS      = "rss"
+	SourceKindJSONFeed = "jsonfeed"
+	SourceKindHTML     = "html"
+	SourceKindHN       = "hackernews"
+	SourceKindReddit   = "reddit"
+	SourceKindArxiv    = "arxiv"
+	SourceKindTelegram = "telegram"
+	SourceKindChannel  = "channel"
+	SourceKindManual   = "manual"
+)
+
+// Range of source priorities accepted when a source is added.
+const (
+	MinSourcePriority = 1
+	MaxSourcePriority = 10
+)
 
 type Item struct {
-	Title      string
-	Categories []string
-	Link       string
-	Date       time.Time
-	Summary    string
-	SourceName string
+	Title           string
+	Categories      []string
+	PrimaryCategory string
+	Authors         []string
+	Link            string
+	CommentsLink    string
+	GUID            string
+	ImageURL        string
+	Date            time.Time
+	Updated         time.Time
+	Summary         string
+	Content         string
+	SourceName      string
+	// CanonicalLink and Fingerprint are set while the fetcher processes the
+	// item.
+	CanonicalLink string
+	Fingerprint   uint64
 }
 
 type Source struct {
+	ID             int64
+	Name           string
+	FeedURL        string
+	Priority       int64
+	Group          string
+	Kind           string
+	Config         json.RawMessage
+	ETag           string
+	LastModified   string
+	FetchInterval  time.Duration
+	FeedHints      FeedHints
+	NextFetchAt    time.Time
+	Health         SourceHealth
+	Disabled       bool
+	DisabledReason string
+	ChannelID      int64
+	Filter         string
+	WebSub         WebSub
+	HTTP           HTTPOptions
+	CreatedAt      time.Time
+}
+
+// IsStale reports whether the source has produced no new items for longer
+// than staleAfter. A zero staleAfter disables the check.
+func (s Source) IsStale(staleAfter time.Duration, now time.Time) bool {
+	if staleAfter <= 0 || s.Kind == SourceKindManual {
+		return false
+	}
+
+	lastNewItem := s.Health.LastNewItemAt
+	if lastNewItem.IsZero() {
+		lastNewItem = s.CreatedAt
+	}
+
+	if lastNewItem.IsZero() {
+		return false
+	}
+
+	return now.Sub(lastNewItem) > staleAfter
+}
+
+// IsPushed reports whether the source's articles arrive on their own, from a
+// linked channel or from manual submissions, so it must not be polled.
+func (s Source) IsPushed() bool {
+	return s.ChannelID != 0 || s.Kind == SourceKindChannel || s.Kind == SourceKindManual
+}
+
+// HTTPOptions are the settings of the requests made for a source. Headers,
+// credentials, cookies and the proxy URL are secrets.
+type HTTPOptions struct {
+	UserAgent          string
+	Headers            map[string]string
+	Username           string
+	Password           string
+	BearerToken        string
+	Cookies            map[string]string
+	ProxyURL           string
+	InsecureSkipVerify bool
+	Timeout            time.Duration
+}
+
+// IsZero reports whether no option is set.
+func (o HTTPOptions) IsZero() bool {
+	return o.UserAgent == "" && len(o.Headers) == 0 && o.Username == "" && o.Password == "" &&
+		o.BearerToken == "" && len(o.Cookies) == 0 && o.ProxyURL == "" && !o.InsecureSkipVerify && o.Timeout == 0
+}
+
+// SourceHealth is the fetch history of a source.
+type SourceHealth struct {
+	LastSuccessAt       time.Time
+	LastErrorAt         time.Time
+	LastError           string
+	ConsecutiveFailures int
+	LastItemsCount      int
+	LastNewItemAt       time.Time
+	// ThrottleCount is how many fetches the source's host refused because
+	// of the request rate.
+	ThrottleCount   int
+	LastThrottledAt time.Time
+	ThrottledUntil  time.Time
+}
+
+// IsThrottled reports whether the source's host asked for requests to stop
+// until after now.
+func (h SourceHealth) IsThrottled(now time.Time) bool {
+	return h.ThrottledUntil.After(now)
+}
+
+// DroppedItem is a fetched item that a stage of the fetcher's pipeline
+// dropped. An item dropped again on later fetches is counted, not repeated.
+type DroppedItem struct {
 	ID        int64
-	Name      string
-	FeedURL   string
-	Priority  int64
-	CreatedAt time.Time
+	SourceID  int64
+	Title     string
+	Link      string
+	Stage     string
+	Reason    string
+	Count     int
+	DroppedAt time.Time
+}
+
+// WebSub subscription states.
+const (
+	WebSubPending = "pending"
+	WebSubActive  = "active"
+	WebSubDenied  = "denied"
+	WebSubQuiet   = "quiet"
+)
+
+// FeedHints is the polling schedule a feed published on its last full fetch.
+type FeedHints struct {
+	Interval  time.Duration
+	SkipHours []int
+}
+
+// WebSub is the push subscription of a feed at the hub it advertises.
+type WebSub struct {
+	Hub            string
+	Topic          string
+	Secret         string
+	State          string
+	RequestedAt    time.Time
+	VerifiedAt     time.Time
+	LeaseExpiresAt time.Time
+	LastPushAt     time.Time
+}
+
+// Active reports whether the hub verified the subscription and its lease
+// has not expired.
+func (w WebSub) Active(now time.Time) bool {
+	return w.State == WebSubActive && w.LeaseExpiresAt.After(now)
+}
+
+// LastHeardAt returns when the hub last delivered content or verified the
+// subscription.
+func (w WebSub) LastHeardAt() time.Time {
+	if w.LastPushAt.After(w.VerifiedAt) {
+		return w.LastPushAt
+	}
+
+	return w.VerifiedAt
 }
 
 type Article struct {
-	ID          int64
-	SourceID    int64
-	Title       string
-	Link        string
-	Summary     string
-	PublishedAt time.Time
-	PostedAt    time.Time
-	CreatedAt   time.Time
+	ID                 int64
+	SourceID           int64
+	Title              string
+	Link               string
+	CanonicalLink      string
+	CommentsLink       string
+	Summary            string
+	Authors            []string
+	PrimaryCategory    string
+	Categories         []string
+	Tags               []string
+	GUID               string
+	ImageURL           string
+	Content            string
+	Text               string
+	LeadImageURL       string
+	Extraction         string
+	ExtractionAttempts int
+	Fingerprint        uint64
+	PublishNext        bool
+	PublishedAt        time.Time
+	UpdatedAt          time.Time
+	PostedAt           time.Time
+	CreatedAt          time.Time
+}
+
+// StoreResult tells what storing an article did.
+type StoreResult int
+
+const (
+	// ArticleUnchanged is an article that was already stored and was left
+	// as it was.
+	ArticleUnchanged StoreResult = iota
+	// ArticleInserted is a new article.
+	ArticleInserted
+	// ArticleUpdated is an article that was already stored and is now
+	// queued to be published next.
+	ArticleUpdated
+)
+
+// Article text extraction states. Extraction is pending for new articles,
+// done once the page's text is stored, and failed when every attempt failed.
+const (
+	ExtractionPending = "pending"
+	ExtractionDone    = "done"
+	ExtractionFailed  = "failed"
+	ExtractionSkipped = "skipped"
+)
+
+// DuplicateMatch is the earlier article a new one was found to repeat.
+type DuplicateMatch struct {
+	ArticleID int64
+	Title     string
+	Link      string
+	Distance  int
+}
+
+// Duplicate is an item that was dropped, or an article that was not posted,
+// because it repeats an earlier article.
+type Duplicate struct {
+	ID         int64
+	SourceID   int64
+	Title      string
+	Link       string
+	Match      DuplicateMatch
+	DetectedAt time.Time
+}
+
+// Filter rule actions and match types.
+const (
+	FilterActionInclude = "include"
+	FilterActionExclude = "exclude"
+
+	FilterMatchKeyword  = "keyword"
+	FilterMatchRegex    = "regex"
+	FilterMatchCategory = "category"
+	FilterMatchAuthor   = "author"
+)
+
+// FilterRule decides whether fetched items are stored. Rules with a zero
+// SourceID apply to every source.
+type FilterRule struct {
+	ID        int64
+	SourceID  int64
+	Action    string
+	Match     string
+	Pattern   string
+	CreatedAt time.Time
 }
@@ -3,24 +3,25 @@ package notifier
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
-	"regexp"
 	"strings"
 	"time"
 
-	"github.com/go-shiori/go-readability"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
 
 	"neuro_scout_bot_v1/internal/botkit/markup"
+	"neuro_scout_bot_v1/internal/extract"
+	"neuro_scout_bot_v1/internal/fingerprint"
 	"neuro_scout_bot_v1/internal/model"
 )
 
 type ArticleProvider interface {
 	AllNotPosted(ctx context.Context, since time.Time, limit uint64) ([]model.Article, error)
 	MarkAsPosted(ctx context.Context, article model.Article) error
-	FindRecentUniqueTitles(ctx context.Context, title string, since time.Time) (bool, error)
+	FindPostedDuplicate(ctx context.Context, article model.Article, since time.Time, maxDistance int) (model.DuplicateMatch, bool, error)
+	RecordDuplicate(ctx context.Context, duplicate model.Duplicate) error
+	LinkPosted(ctx context.Context, article model.Article) (bool, error)
 	HighPriorityNotPosted(ctx context.Context, priorityThreshold int64, since time.Time, limit uint64) ([]model.Article, error)
 }
 
@@ -35,6 +36,7 @@ type Notifier struct {
 	sendInterval     time.Duration
 	lookupTimeWindow time.Duration
 	channelID        int64
+	dedup            fingerprint.Policy
 }
 
 func New(
@@ -44,6 +46,7 @@ func New(
 	sendInterval time.Duration,
 	lookupTimeWindow time.Duration,
 	channelID int64,
+	dedup fingerprint.Policy,
 ) *Notifier {
 	return &Notifier{
 		articles:         articleProvider,
@@ -52,6 +55,7 @@ func New(
 		sendInterval:     sendInterval,
 		lookupTimeWindow: lookupTimeWindow,
 		channelID:        channelID,
+		dedup:            dedup,
 	}
 }
 
@@ -103,17 +107,14 @@ func (n *Notifier) ProcessHighPriorityArticles(ctx context.Context) error {
 	log.Printf("[INFO] Found %d high priority articles for auto-publishing", len(highPriorityArticles))
 
 	for _, article := range highPriorityArticles {
-		isUnique, err := n.articles.FindRecentUniqueTitles(ctx, article.Title, time.Now().AddDate(0, 0, -7))
-		if err != nil {
-			log.Printf("[WARN] Failed to check title uniqueness for high priority article: %v", err)
+		if n.duplicatesPosted(ctx, article) {
 			if err := n.articles.MarkAsPosted(ctx, article); err != nil {
 				log.Printf("[ERROR] Failed to mark high priority article as posted: %v", err)
 			}
 			continue
 		}
 
-		if !isUnique {
-			log.Printf("[INFO] Skipping non-unique high priority article: %s", article.Title)
+		if n.alreadyPosted(ctx, article) {
 			if err := n.articles.MarkAsPosted(ctx, article); err != nil {
 				log.Printf("[ERROR] Failed to mark high priority article as posted: %v", err)
 			}
@@ -145,14 +146,11 @@ func (n *Notifier) SelectAndSendArticle(ctx context.Context) error {
 
 	article := topOneArticles[0]
 
-	isUnique, err := n.articles.FindRecentUniqueTitles(ctx, article.Title, time.Now().AddDate(0, 0, -7))
-	if err != nil {
-		log.Printf("[WARN] Failed to check title uniqueness: %v", err)
+	if n.duplicatesPosted(ctx, article) {
 		return n.articles.MarkAsPosted(ctx, article)
 	}
 
-	if !isUnique {
-		log.Printf("[INFO] Skipping non-unique article: %s", article.Title)
+	if n.alreadyPosted(ctx, article) {
 		return n.articles.MarkAsPosted(ctx, article)
 	}
 
@@ -168,66 +166,65 @@ func (n *Notifier) SelectAndSendArticle(ctx context.Context) error {
 	return n.articles.MarkAsPosted(ctx, article)
 }
 
-var redundantNewLines = regexp.MustCompile(`\n{3,}`)
+// duplicatesPosted reports whether a recently posted article says nearly the
+// same thing. Errors let the article through.
+func (n *Notifier) duplicatesPosted(ctx context.Context, article model.Article) bool {
+	match, found, err := n.articles.FindPostedDuplicate(ctx, article, time.Now().Add(-n.dedup.Lookback), n.dedup.MaxDistance)
+	if err != nil {
+		log.Printf("[WARN] Failed to look for posted duplicates of %q: %v", article.Title, err)
+		return false
+	}
+	if !found {
+		return false
+	}
 
-func (n *Notifier) extractSummary(article model.Article) (string, error) {
-	log.Printf("[INFO] Extracting summary for article: %s", article.Title)
+	log.Printf("[INFO] Skipping article %q that duplicates posted article %q (distance %d)", article.Title, match.Title, match.Distance)
 
-	// Перевіряємо наявність summarizer
-	if n.summarizer == nil {
-		log.Printf("[INFO] Summarizer is not configured, skipping summary generation")
-		return "", nil
+	if err := n.articles.RecordDuplicate(ctx, model.Duplicate{
+		SourceID: article.SourceID,
+		Title:    article.Title,
+		Link:     article.Link,
+		Match:    match,
+	}); err != nil {
+		log.Printf("[WARN] Failed to record duplicate %s: %v", article.Link, err)
 	}
 
-	var r io.Reader
-
-	if article.Summary != "" {
-		log.Printf("[INFO] Using existing article summary")
-		r = strings.NewReader(article.Summary)
-	} else {
-		log.Printf("[INFO] Article has no summary, fetching content from URL: %s", article.Link)
-		client := &http.Client{
-			Timeout: 30 * time.Second,
-		}
+	return true
+}
 
-		req, err := http.NewRequest("GET", article.Link, nil)
-		if err != nil {
-			log.Printf("[ERROR] Failed to create request: %v", err)
-			return "", err
-		}
+// alreadyPosted reports whether the canonical link was already posted.
+// Errors let the article through.
+func (n *Notifier) alreadyPosted(ctx context.Context, article model.Article) bool {
+	posted, err := n.articles.LinkPosted(ctx, article)
+	if err != nil {
+		log.Printf("[WARN] Failed to check whether the link of %q was posted: %v", article.Title, err)
+		return false
+	}
 
-		// Додамо User-Agent щоб уникнути блокування
-		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+	if posted {
+		log.Printf("[INFO] Skipping article with an already posted link: %s", article.Link)
+	}
 
-		resp, err := client.Do(req)
-		if err != nil {
-			log.Printf("[ERROR] Failed to fetch article from %s: %v", article.Link, err)
-			return "", err
-		}
-		defer resp.Body.Close()
+	return posted
+}
 
-		if resp.StatusCode != http.StatusOK {
-			log.Printf("[ERROR] Bad response status: %s for URL: %s", resp.Status, article.Link)
-			return "", fmt.Errorf("bad response status: %s", resp.Status)
-		}
+func (n *Notifier) extractSummary(article model.Article) (string, error) {
+	log.Printf("[INFO] Extracting summary for article: %s", article.Title)
 
-		r = resp.Body
+	// Перевіряємо наявність summarizer
+	if n.summarizer == nil {
+		log.Printf("[INFO] Summarizer is not configured, skipping summary generation")
+		return "", nil
 	}
 
-	log.Printf("[INFO] Parsing article content with readability")
-	doc, err := readability.FromReader(r, nil)
+	textContent, err := extract.ArticleText(article)
 	if err != nil {
-		log.Printf("[ERROR] Failed to parse article with readability: %v", err)
+		log.Printf("[WARN] Article %q has no stored text to summarize: %v", article.Title, err)
 		return "", err
 	}
 
-	textContent := cleanupText(doc.TextContent)
-
 	if len(textContent) < 100 {
 		log.Printf("[WARN] Article text is too short (%d chars), may not generate good summary", len(textContent))
-		if len(textContent) < 20 {
-			return "", fmt.Errorf("article text is too short to summarize")
-		}
 	}
 
 	contentPreview := textContent
@@ -264,22 +261,19 @@ func (n *Notifier) extractSummary(article model.Article) (string, error) {
 	return "\n\n" + summary, nil
 }
 
-func cleanupText(text string) string {
-	return redundantNewLines.ReplaceAllString(text, "\n")
-}
-
 func (n *Notifier) sendArticle(article model.Article, summary string) error {
 	// Перевіряємо, чи summary не є порожнім
-	const msgFormatWithSummary = "*%s*%s\n\n%s"
-	const msgFormatWithoutSummary = "*%s*\n\n%s"
+	const msgFormatWithSummary = "*%s*%s%s\n\n%s"
+	const msgFormatWithoutSummary = "*%s*%s\n\n%s"
 
 	var formattedMsg string
 	if summary != "" {
 		formattedMsg = fmt.Sprintf(
 			msgFormatWithSummary,
 			markup.EscapeForMarkdown(article.Title),
+			markup.EscapeForMarkdown(articleByline(article)),
 			markup.EscapeForMarkdown(summary),
-			markup.EscapeForMarkdown(article.Link),
+			markup.EscapeForMarkdown(articleLinks(article)),
 		)
 		log.Printf("[INFO] Sending article to channel with summary. Title: %s, Summary length: %d, Message length: %d",
 			article.Title, len(summary), len(formattedMsg))
@@ -287,7 +281,8 @@ func (n *Notifier) sendArticle(article model.Article, summary string) error {
 		formattedMsg = fmt.Sprintf(
 			msgFormatWithoutSummary,
 			markup.EscapeForMarkdown(article.Title),
-			markup.EscapeForMarkdown(article.Link),
+			markup.EscapeForMarkdown(articleByline(article)),
+			markup.EscapeForMarkdown(articleLinks(article)),
 		)
 		log.Printf("[INFO] Sending article to channel without summary. Title: %s, Message length: %d",
 			article.Title, len(formattedMsg))
@@ -306,6 +301,36 @@ func (n *Notifier) sendArticle(article model.Article, summary string) error {
 	return nil
 }
 
+const maxBylineAuthors = 3
+
+// articleByline returns the " — Authors (category)" suffix posted after the
+// title of papers. Articles without a primary category get none.
+func articleByline(article model.Article) string {
+	if article.PrimaryCategory == "" {
+		return ""
+	}
+
+	if len(article.Authors) == 0 {
+		return fmt.Sprintf(" (%s)", article.PrimaryCategory)
+	}
+
+	authors := strings.Join(lo.Slice(article.Authors, 0, maxBylineAuthors), ", ")
+	if len(article.Authors) > maxBylineAuthors {
+		authors += " et al."
+	}
+
+	return fmt.Sprintf(" — %s (%s)", authors, article.PrimaryCategory)
+}
+
+// articleLinks returns the article's link and its discussion link, if any.
+func articleLinks(article model.Article) string {
+	if article.CommentsLink == "" || article.CommentsLink == article.Link {
+		return article.Link
+	}
+
+	return fmt.Sprintf("%s\n💬 %s", article.Link, article.CommentsLink)
+}
+
 func (n *Notifier) PublishArticle(ctx context.Context, article model.Article) error {
 	summary, err := n.extractSummary(article)
 	if err != nil {
@@ -0,0 +1,221 @@
+// Package opml imports and exports the source list as OPML, the format feed
+// readers use to move subscriptions around.
+package opml
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"neuro_scout_bot_v1/internal/model"
+	sourcelib "neuro_scout_bot_v1/internal/source"
+)
+
+// DefaultPriority is given to imported sources. It is below the threshold
+// for auto-publishing.
+const DefaultPriority = 5
+
+// groupSeparator joins the names of nested folders into one group name.
+const groupSeparator = " / "
+
+type Document struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+type Head struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Feed is a subscription read from or written to an OPML document.
+type Feed struct {
+	Name  string
+	URL   string
+	Group string
+}
+
+// Parse reads an OPML document and returns its feeds, grouped by their
+// folders.
+func Parse(r io.Reader) ([]Feed, error) {
+	var doc Document
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OPML: %w", err)
+	}
+
+	var feeds []Feed
+	collectFeeds(doc.Body.Outlines, nil, &feeds)
+
+	return feeds, nil
+}
+
+func collectFeeds(outlines []Outline, folders []string, feeds *[]Feed) {
+	for _, outline := range outlines {
+		name := strings.TrimSpace(outline.Title)
+		if name == "" {
+			name = strings.TrimSpace(outline.Text)
+		}
+
+		feedURL := strings.TrimSpace(outline.XMLURL)
+		if feedURL == "" {
+			collectFeeds(outline.Outlines, append(folders, name), feeds)
+			continue
+		}
+
+		if name == "" {
+			name = feedURL
+		}
+
+		*feeds = append(*feeds, Feed{
+			Name:  name,
+			URL:   feedURL,
+			Group: strings.Join(folders, groupSeparator),
+		})
+	}
+}
+
+// Write renders the feeds as an OPML document, one folder per group.
+func Write(w io.Writer, title string, feeds []Feed, now time.Time) error {
+	doc := Document{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: now.UTC().Format(time.RFC1123Z),
+		},
+	}
+
+	folders := make(map[string]int)
+	for _, feed := range feeds {
+		outline := Outline{
+			Text:   feed.Name,
+			Title:  feed.Name,
+			Type:   "rss",
+			XMLURL: feed.URL,
+		}
+
+		if feed.Group == "" {
+			doc.Body.Outlines = append(doc.Body.Outlines, outline)
+			continue
+		}
+
+		i, ok := folders[feed.Group]
+		if !ok {
+			i = len(doc.Body.Outlines)
+			folders[feed.Group] = i
+			doc.Body.Outlines = append(doc.Body.Outlines, Outline{Text: feed.Group, Title: feed.Group})
+		}
+
+		doc.Body.Outlines[i].Outlines = append(doc.Body.Outlines[i].Outlines, outline)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write OPML: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type SourceStorage interface {
+	Sources(ctx context.Context) ([]model.Source, error)
+	Add(ctx context.Context, source model.Source) (int64, error)
+}
+
+// ImportReport tells what happened to each feed of an import.
+type ImportReport struct {
+	Added      []Feed
+	Duplicates []Feed
+	Failed     map[string]error
+}
+
+// Import adds the feeds as RSS sources. Feeds whose URL is already a source,
+// or repeats an earlier feed of the same import, are skipped.
+func Import(ctx context.Context, storage SourceStorage, feeds []Feed) (ImportReport, error) {
+	existing, err := storage.Sources(ctx)
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	known := make(map[string]struct{}, len(existing))
+	for _, source := range existing {
+		known[sourcelib.NormalizeFeedURL(source.FeedURL)] = struct{}{}
+	}
+
+	report := ImportReport{Failed: make(map[string]error)}
+	for _, feed := range feeds {
+		key := sourcelib.NormalizeFeedURL(feed.URL)
+		if _, ok := known[key]; ok {
+			report.Duplicates = append(report.Duplicates, feed)
+			continue
+		}
+
+		if _, err := storage.Add(ctx, model.Source{
+			Name:      feed.Name,
+			FeedURL:   feed.URL,
+			Priority:  DefaultPriority,
+			Kind:      model.SourceKindRSS,
+			Group:     feed.Group,
+			CreatedAt: time.Now().UTC(),
+		}); err != nil {
+			report.Failed[feed.URL] = err
+			continue
+		}
+
+		known[key] = struct{}{}
+		report.Added = append(report.Added, feed)
+	}
+
+	return report, nil
+}
+
+// Export returns the feeds of all sources that have a feed URL, sorted by
+// group and name.
+func Export(ctx context.Context, storage SourceStorage) ([]Feed, error) {
+	sources, err := storage.Sources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var feeds []Feed
+	for _, source := range sources {
+		if source.FeedURL == "" {
+			continue
+		}
+
+		feeds = append(feeds, Feed{Name: source.Name, URL: source.FeedURL, Group: source.Group})
+	}
+
+	sort.SliceStable(feeds, func(i, j int) bool {
+		if feeds[i].Group != feeds[j].Group {
+			return feeds[i].Group < feeds[j].Group
+		}
+		return feeds[i].Name < feeds[j].Name
+	})
+
+	return feeds, nil
+}
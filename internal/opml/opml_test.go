@@ -0,0 +1,98 @@
+package opml
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+const sample = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="1.0">
+  <head><title>Reader export</title></head>
+  <body>
+    <outline text="Research">
+      <outline text="arXiv cs.LG" type="rss" xmlUrl="http://export.arxiv.org/rss/cs.LG"/>
+      <outline text="Labs">
+        <outline title="DeepMind Blog" text="DM" type="rss" xmlUrl="https://deepmind.google/blog/rss.xml"/>
+      </outline>
+    </outline>
+    <outline text="Simon Willison" type="rss" xmlUrl="https://simonwillison.net/atom/everything/" htmlUrl="https://simonwillison.net"/>
+    <outline text="Empty folder"/>
+  </body>
+</opml>`
+
+func TestParse(t *testing.T) {
+	feeds, err := Parse(strings.NewReader(sample))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Feed{
+		{Name: "arXiv cs.LG", URL: "http://export.arxiv.org/rss/cs.LG", Group: "Research"},
+		{Name: "DeepMind Blog", URL: "https://deepmind.google/blog/rss.xml", Group: "Research / Labs"},
+		{Name: "Simon Willison", URL: "https://simonwillison.net/atom/everything/"},
+	}, feeds)
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	feeds := []Feed{
+		{Name: "A", URL: "https://a.example/feed", Group: "News"},
+		{Name: "B", URL: "https://b.example/feed"},
+		{Name: "C & D", URL: "https://c.example/feed?x=1&y=2", Group: "News"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, "Sources", feeds, time.Date(2025, 6, 8, 0, 0, 0, 0, time.UTC)))
+
+	parsed, err := Parse(&buf)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, feeds, parsed)
+}
+
+type memoryStorage struct {
+	sources []model.Source
+	failURL string
+}
+
+func (m *memoryStorage) Sources(context.Context) ([]model.Source, error) {
+	return m.sources, nil
+}
+
+func (m *memoryStorage) Add(_ context.Context, source model.Source) (int64, error) {
+	if source.FeedURL == m.failURL {
+		return 0, errors.New("boom")
+	}
+
+	source.ID = int64(len(m.sources) + 1)
+	m.sources = append(m.sources, source)
+	return source.ID, nil
+}
+
+func TestImport(t *testing.T) {
+	storage := &memoryStorage{
+		sources: []model.Source{{ID: 1, Name: "Simon", FeedURL: "https://simonwillison.net/atom/everything"}},
+		failURL: "https://broken.example/feed",
+	}
+
+	report, err := Import(context.Background(), storage, []Feed{
+		{Name: "Simon again", URL: "http://SimonWillison.net/atom/everything/"},
+		{Name: "arXiv", URL: "http://export.arxiv.org/rss/cs.LG", Group: "Research"},
+		{Name: "arXiv copy", URL: "https://export.arxiv.org/rss/cs.LG"},
+		{Name: "Broken", URL: "https://broken.example/feed"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Feed{{Name: "arXiv", URL: "http://export.arxiv.org/rss/cs.LG", Group: "Research"}}, report.Added)
+	assert.Len(t, report.Duplicates, 2)
+	assert.Contains(t, report.Failed, "https://broken.example/feed")
+
+	require.Len(t, storage.sources, 2)
+	assert.Equal(t, "Research", storage.sources[1].Group)
+	assert.Equal(t, int64(DefaultPriority), storage.sources[1].Priority)
+}
@@ -0,0 +1,190 @@
+// Package ratelimit limits the requests to each host and backs off from
+// hosts that answer 429 Too Many Requests.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket per host, whose hosts can be paused. A nil
+// *Limiter does not limit anything.
+type Limiter struct {
+	interval time.Duration
+	burst    int
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens      float64
+	updated     time.Time
+	pausedUntil time.Time
+}
+
+// ThrottledError is returned for a host that is paused.
+type ThrottledError struct {
+	Host  string
+	Until time.Time
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("host %s is throttled until %s", e.Host, e.Until.Format(time.RFC3339))
+}
+
+// NewLimiter returns a limiter that lets burst requests through to a host at
+// once and one more every interval. A zero interval only honors pauses.
+func NewLimiter(interval time.Duration, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Limiter{
+		interval: interval,
+		burst:    burst,
+		buckets:  make(map[string]*bucket),
+	}
+}
+
+// Wait takes a token for the host, blocking until one is available, the
+// host's pause is over, or the context is cancelled.
+func (l *Limiter) Wait(ctx context.Context, host string) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	wait := l.reserve(normalizeHost(host), time.Now())
+	if err := Sleep(ctx, wait); err != nil {
+		l.cancel(normalizeHost(host))
+		return err
+	}
+
+	return nil
+}
+
+// Pause stops requests to the host until the given time. Pauses only ever
+// get longer.
+func (l *Limiter) Pause(host string, until time.Time) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucket(normalizeHost(host), time.Now())
+	if until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
+
+// PausedUntil returns when the host's pause ends, or the zero time when the
+// host is not paused.
+func (l *Limiter) PausedUntil(host string) time.Time {
+	if l == nil {
+		return time.Time{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[normalizeHost(host)]
+	if !ok || !b.pausedUntil.After(time.Now()) {
+		return time.Time{}
+	}
+
+	return b.pausedUntil
+}
+
+// reserve takes a token, possibly one that is yet to come, and returns how
+// long to wait for it.
+func (l *Limiter) reserve(host string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucket(host, now)
+
+	var wait time.Duration
+	if l.interval > 0 {
+		b.tokens--
+		if b.tokens < 0 {
+			wait = time.Duration(-b.tokens * float64(l.interval))
+		}
+	}
+
+	if pause := b.pausedUntil.Sub(now); pause > wait {
+		wait = pause
+	}
+
+	return wait
+}
+
+// cancel gives back the token of a wait that was cancelled.
+func (l *Limiter) cancel(host string) {
+	if l.interval <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.bucket(host, time.Now()).tokens++
+}
+
+// bucket returns the host's bucket refilled up to now. It must be called
+// with the lock held.
+func (l *Limiter) bucket(host string, now time.Time) *bucket {
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), updated: now}
+		l.buckets[host] = b
+		return b
+	}
+
+	if l.interval > 0 && now.After(b.updated) {
+		b.tokens += float64(now.Sub(b.updated)) / float64(l.interval)
+		if b.tokens > float64(l.burst) {
+			b.tokens = float64(l.burst)
+		}
+	}
+	b.updated = now
+
+	return b
+}
+
+// Sleep waits for d or until the context is cancelled, whichever comes
+// first.
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// HostOf returns the host requests to rawURL are limited by.
+func HostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	return normalizeHost(u.Hostname())
+}
+
+func normalizeHost(host string) string {
+	return strings.ToLower(host)
+}
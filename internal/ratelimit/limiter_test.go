@@ -0,0 +1,189 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Wait(t *testing.T) {
+	limiter := NewLimiter(50*time.Millisecond, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	require.NoError(t, limiter.Wait(ctx, "a.com"))
+	require.NoError(t, limiter.Wait(ctx, "A.com"))
+	require.NoError(t, limiter.Wait(ctx, "b.com"))
+	assert.Less(t, time.Since(start), 40*time.Millisecond, "the burst and other hosts should not wait")
+
+	require.NoError(t, limiter.Wait(ctx, "a.com"))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, "an empty bucket should wait for a token")
+}
+
+func TestLimiter_MinimumDelay(t *testing.T) {
+	limiter := NewLimiter(50*time.Millisecond, 1)
+	ctx := context.Background()
+
+	start := time.Now()
+	require.NoError(t, limiter.Wait(ctx, "a.com"))
+	require.NoError(t, limiter.Wait(ctx, "b.com"))
+	assert.Less(t, time.Since(start), 40*time.Millisecond, "different hosts should not wait")
+
+	require.NoError(t, limiter.Wait(ctx, "a.com"))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, "same host should wait for the delay")
+
+	require.NoError(t, limiter.Wait(ctx, "a.com"))
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond, "same host should wait for the delay again")
+}
+
+func TestLimiter_WaitCancelled(t *testing.T) {
+	limiter := NewLimiter(time.Hour, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, limiter.Wait(ctx, "a.com"))
+
+	cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx, "a.com"), context.Canceled)
+}
+
+func TestLimiter_Pause(t *testing.T) {
+	limiter := NewLimiter(0, 1)
+	until := time.Now().Add(time.Hour)
+
+	limiter.Pause("a.com", until)
+	limiter.Pause("a.com", time.Now().Add(time.Minute))
+	assert.Equal(t, until, limiter.PausedUntil("a.com"), "pauses only get longer")
+	assert.True(t, limiter.PausedUntil("b.com").IsZero())
+
+	_, err := limiter.Acquire(context.Background(), "a.com")
+	var throttled *ThrottledError
+	require.ErrorAs(t, err, &throttled)
+	assert.Equal(t, until, throttled.Until)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx, "a.com"), context.DeadlineExceeded)
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/busy" {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+	}))
+	defer server.Close()
+
+	limiter := NewLimiter(time.Hour, 1)
+	client := &http.Client{Transport: NewTransport(nil, limiter), Timeout: time.Second}
+	host := HostOf(server.URL)
+
+	ctx, err := limiter.Acquire(context.Background(), host)
+	require.NoError(t, err)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/busy", nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err, "the reserved token should be used instead of waiting")
+	resp.Body.Close()
+
+	statusErr := CheckResponse(resp)
+	until, throttled := ThrottledUntil(statusErr)
+	require.True(t, throttled)
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute), until, 5*time.Second)
+	assert.WithinDuration(t, until, limiter.PausedUntil(host), 5*time.Second)
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	timeoutCtx, cancel := context.WithTimeout(req.Context(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = client.Do(req.WithContext(timeoutCtx))
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "the token is used up and the host is paused")
+}
+
+func TestTransport_Reservation(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	limiter := NewLimiter(time.Hour, 1)
+	client := &http.Client{Transport: NewTransport(nil, limiter), Timeout: time.Second}
+	host := HostOf(server.URL)
+
+	get := func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	other, err := limiter.Acquire(context.Background(), "other.example.com")
+	require.NoError(t, err)
+	require.NoError(t, get(other), "a reservation for another host does not cover this one, whose bucket is full")
+	assert.ErrorIs(t, get(other), context.DeadlineExceeded, "nor does it let more requests through")
+
+	limiter = NewLimiter(time.Hour, 1)
+	client.Transport = NewTransport(nil, limiter)
+
+	reserved, err := limiter.Acquire(context.Background(), host)
+	require.NoError(t, err)
+	require.NoError(t, get(reserved), "the first request to the host spends the reserved token")
+	assert.ErrorIs(t, get(reserved), context.DeadlineExceeded, "later requests wait for their own token")
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 6, 17, 12, 0, 0, 0, time.UTC)
+
+	header := http.Header{}
+	_, ok := RetryAfter(header, now)
+	assert.False(t, ok)
+
+	header.Set("Retry-After", "30")
+	d, ok := RetryAfter(header, now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, d)
+
+	header.Set("Retry-After", "Tue, 17 Jun 2025 12:05:00 GMT")
+	d, ok = RetryAfter(header, now)
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Minute, d)
+
+	header.Set("Retry-After", "soon")
+	_, ok = RetryAfter(header, now)
+	assert.False(t, ok)
+}
+
+func TestCheckResponse(t *testing.T) {
+	assert.NoError(t, CheckResponse(&http.Response{StatusCode: http.StatusOK}))
+
+	var status *StatusError
+	err := CheckResponse(&http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Header: http.Header{}})
+	require.ErrorAs(t, err, &status)
+	assert.False(t, status.Temporary())
+	assert.EqualError(t, err, "unexpected response status: 404 Not Found")
+
+	err = CheckResponse(&http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}})
+	require.ErrorAs(t, err, &status)
+	assert.True(t, status.Temporary())
+	assert.False(t, status.Throttled(), "503 without Retry-After is an outage, not throttling")
+}
@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatusError is a response with a non-2xx status.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	// RetryAt is when the server asked to be tried again, zero when it did
+	// not say.
+	RetryAt time.Time
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected response status: %s", e.Status)
+}
+
+// Throttled reports whether the server refused the request because of the
+// request rate.
+func (e *StatusError) Throttled() bool {
+	return e.StatusCode == http.StatusTooManyRequests ||
+		(e.StatusCode == http.StatusServiceUnavailable && !e.RetryAt.IsZero())
+}
+
+// Temporary reports whether the request can succeed when repeated later.
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusRequestTimeout || e.Throttled()
+}
+
+// CheckResponse returns a *StatusError for a response with a non-2xx
+// status, and nil otherwise.
+func CheckResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	err := &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	if until, ok := throttledUntil(resp, time.Now()); ok {
+		err.RetryAt = until
+	}
+
+	return err
+}
+
+// ThrottledUntil reports whether err says the host is throttling requests,
+// and until when.
+func ThrottledUntil(err error) (time.Time, bool) {
+	var throttled *ThrottledError
+	if errors.As(err, &throttled) {
+		return throttled.Until, true
+	}
+
+	var status *StatusError
+	if errors.As(err, &status) && status.Throttled() {
+		return status.RetryAt, true
+	}
+
+	return time.Time{}, false
+}
+
+// RetryAfter reads the Retry-After header, given either in seconds or as an
+// HTTP date.
+func RetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	if at.Before(now) {
+		return 0, true
+	}
+
+	return at.Sub(now), true
+}
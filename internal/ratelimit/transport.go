@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// defaultPause is how long a host that answered 429 without a Retry-After
+// header is left alone.
+const defaultPause = time.Minute
+
+// Transport is an http.RoundTripper that waits for the limiter before every
+// request and pauses hosts that ask for it.
+type Transport struct {
+	base    http.RoundTripper
+	limiter *Limiter
+}
+
+// NewTransport wraps base, or http.DefaultTransport when base is nil.
+func NewTransport(base http.RoundTripper, limiter *Limiter) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{base: base, limiter: limiter}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Hostname()
+
+	if !reserved(req.Context(), host) {
+		if err := t.limiter.Wait(req.Context(), host); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if until, ok := throttledUntil(resp, time.Now()); ok {
+		t.limiter.Pause(host, until)
+	}
+
+	return resp, nil
+}
+
+type reservationKey struct{}
+
+type reservation struct {
+	host string
+	used atomic.Bool
+}
+
+// Acquire takes a token for the first request to the host made with the
+// returned context. A paused host is a *ThrottledError.
+func (l *Limiter) Acquire(ctx context.Context, host string) (context.Context, error) {
+	if until := l.PausedUntil(host); !until.IsZero() {
+		return ctx, &ThrottledError{Host: host, Until: until}
+	}
+
+	if err := l.Wait(ctx, host); err != nil {
+		return ctx, err
+	}
+
+	return context.WithValue(ctx, reservationKey{}, &reservation{host: normalizeHost(host)}), nil
+}
+
+func reserved(ctx context.Context, host string) bool {
+	r, ok := ctx.Value(reservationKey{}).(*reservation)
+	if !ok || r.host != normalizeHost(host) {
+		return false
+	}
+
+	return r.used.CompareAndSwap(false, true)
+}
+
+// throttledUntil reports whether the response asks to stop requests to the
+// host, and until when.
+func throttledUntil(resp *http.Response, now time.Time) (time.Time, bool) {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		if retryAfter, ok := RetryAfter(resp.Header, now); ok {
+			return now.Add(retryAfter), true
+		}
+		return now.Add(defaultPause), true
+	case http.StatusServiceUnavailable:
+		if retryAfter, ok := RetryAfter(resp.Header, now); ok {
+			return now.Add(retryAfter), true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,97 @@
+// Package secret encrypts the secrets the bot keeps in the database, such
+// as the credentials of sources, with a key from the config.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sealedPrefix marks sealed values and the format version.
+const sealedPrefix = "v1:"
+
+// minKeyLength is the shortest key accepted.
+const minKeyLength = 16
+
+// ErrNoKey is returned when secrets have to be sealed or opened without a
+// key configured.
+var ErrNoKey = errors.New("no secret key configured")
+
+// Box seals and opens secrets with AES-256-GCM. A nil *Box has no key: it
+// fails with ErrNoKey.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// NewBox returns a box keyed with the SHA-256 of key, which should be a
+// long random string.
+func NewBox(key string) (*Box, error) {
+	if len(key) < minKeyLength {
+		return nil, fmt.Errorf("secret key must be at least %d characters long", minKeyLength)
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Box{aead: aead}, nil
+}
+
+// Seal encrypts plaintext into a printable string.
+func (b *Box) Seal(plaintext []byte) (string, error) {
+	if b == nil {
+		return "", ErrNoKey
+	}
+
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, plaintext, nil)
+
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a string made by Seal.
+func (b *Box) Open(sealed string) ([]byte, error) {
+	if b == nil {
+		return nil, ErrNoKey
+	}
+
+	encoded, ok := strings.CutPrefix(sealed, sealedPrefix)
+	if !ok {
+		return nil, errors.New("unknown secret format")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret: %w", err)
+	}
+
+	nonceSize := b.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("secret is too short")
+	}
+
+	plaintext, err := b.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt secret, is the secret key the one it was sealed with?")
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,37 @@
+package secret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBox(t *testing.T) {
+	box, err := NewBox("a long random secret key")
+	require.NoError(t, err)
+
+	sealed, err := box.Seal([]byte(`{"password":"hunter2"}`))
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "hunter2")
+
+	again, err := box.Seal([]byte(`{"password":"hunter2"}`))
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "every seal uses a fresh nonce")
+
+	plaintext, err := box.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, `{"password":"hunter2"}`, string(plaintext))
+
+	other, err := NewBox("another long random key")
+	require.NoError(t, err)
+	_, err = other.Open(sealed)
+	assert.Error(t, err)
+
+	_, err = NewBox("short")
+	assert.Error(t, err)
+
+	var noKey *Box
+	_, err = noKey.Seal([]byte("x"))
+	assert.ErrorIs(t, err, ErrNoKey)
+}
@@ -0,0 +1,204 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+const (
+	defaultArxivBaseURL    = "https://export.arxiv.org/api/query"
+	defaultArxivMaxResults = 50
+	maxArxivMaxResults     = 200
+)
+
+var (
+	arxivCategory = regexp.MustCompile(`^[a-z-]+(\.[A-Za-z-]+)?$`)
+	// arxivVersion is the version suffix of an abs link. It is dropped so a
+	// revised paper is not stored again.
+	arxivVersion = regexp.MustCompile(`v\d+$`)
+)
+
+// ArxivConfig selects papers from the arXiv API by category and query.
+type ArxivConfig struct {
+	// Categories such as "cs.LG" or "stat.ML". A paper matches if it is in
+	// any of them.
+	Categories []string `json:"categories"`
+	// Query is an arXiv search query, e.g. `ti:transformer AND abs:"sparse"`.
+	// Plain words are searched in all fields.
+	Query      string `json:"query"`
+	MaxResults int    `json:"max_results"`
+	BaseURL    string `json:"base_url"`
+}
+
+func (c ArxivConfig) validate() error {
+	if len(c.Categories) == 0 && strings.TrimSpace(c.Query) == "" {
+		return errors.New("arxiv source config: categories or query is required")
+	}
+
+	for _, category := range c.Categories {
+		if !arxivCategory.MatchString(category) {
+			return fmt.Errorf("arxiv source config: invalid category %q", category)
+		}
+	}
+
+	if c.MaxResults < 0 || c.MaxResults > maxArxivMaxResults {
+		return fmt.Errorf("arxiv source config: max_results must be between 1 and %d", maxArxivMaxResults)
+	}
+
+	return nil
+}
+
+// searchQuery combines the categories and the query into the search_query
+// parameter of the arXiv API.
+func (c ArxivConfig) searchQuery() string {
+	var parts []string
+
+	if len(c.Categories) > 0 {
+		categories := make([]string, 0, len(c.Categories))
+		for _, category := range c.Categories {
+			categories = append(categories, "cat:"+category)
+		}
+		parts = append(parts, "("+strings.Join(categories, " OR ")+")")
+	}
+
+	if query := strings.TrimSpace(c.Query); query != "" {
+		if !strings.Contains(query, ":") {
+			query = "all:" + strconv.Quote(query)
+		}
+		parts = append(parts, "("+query+")")
+	}
+
+	return strings.Join(parts, " AND ")
+}
+
+// ArxivSource reads the newest submissions from the arXiv Atom API, keeping
+// authors, categories and the abstract.
+type ArxivSource struct {
+	SourceId   int64
+	SourceName string
+	Config     ArxivConfig
+	client     *http.Client
+}
+
+func newArxivSource(m model.Source, limiter *ratelimit.Limiter) (Source, error) {
+	config, err := decodeConfig[ArxivConfig](m.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	if config.MaxResults == 0 {
+		config.MaxResults = defaultArxivMaxResults
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = defaultArxivBaseURL
+	}
+
+	return &ArxivSource{
+		SourceId:   m.ID,
+		SourceName: m.Name,
+		Config:     config,
+		client:     newHTTPClient(m, limiter, config.BaseURL),
+	}, nil
+}
+
+func validateArxivConfig(config json.RawMessage) error {
+	arxivConfig, err := decodeConfig[ArxivConfig](config)
+	if err != nil {
+		return err
+	}
+
+	return arxivConfig.validate()
+}
+
+func (s *ArxivSource) Fetch(ctx context.Context) ([]model.Item, error) {
+	query := url.Values{}
+	query.Set("search_query", s.Config.searchQuery())
+	query.Set("sortBy", "submittedDate")
+	query.Set("sortOrder", "descending")
+	query.Set("max_results", strconv.Itoa(s.Config.MaxResults))
+
+	body, _, err := fetchBody(ctx, s.client, s.Config.BaseURL+"?"+query.Encode(), "application/atom+xml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query arXiv: %w", err)
+	}
+
+	feed, err := gofeed.NewParser().Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arXiv response: %w", err)
+	}
+
+	items := make([]model.Item, 0, len(feed.Items))
+	for _, entry := range feed.Items {
+		link := arxivVersion.ReplaceAllString(entry.Link, "")
+		if link == "" || entry.Title == "" {
+			continue
+		}
+
+		var published time.Time
+		if entry.PublishedParsed != nil {
+			published = *entry.PublishedParsed
+		}
+
+		var authors []string
+		for _, author := range entry.Authors {
+			if author.Name != "" {
+				authors = append(authors, author.Name)
+			}
+		}
+
+		items = append(items, model.Item{
+			Title:           collapseSpaces(entry.Title),
+			Categories:      entry.Categories,
+			PrimaryCategory: arxivPrimaryCategory(entry),
+			Authors:         authors,
+			Link:            link,
+			Date:            published,
+			Summary:         collapseSpaces(entry.Description),
+			SourceName:      s.SourceName,
+		})
+	}
+
+	return items, nil
+}
+
+// arxivPrimaryCategory reads the arxiv:primary_category extension, falling
+// back to the first listed category.
+func arxivPrimaryCategory(entry *gofeed.Item) string {
+	for _, ext := range entry.Extensions["arxiv"]["primary_category"] {
+		if term := ext.Attrs["term"]; term != "" {
+			return term
+		}
+	}
+
+	if len(entry.Categories) > 0 {
+		return entry.Categories[0]
+	}
+
+	return ""
+}
+
+func (s *ArxivSource) ID() int64 {
+	return s.SourceId
+}
+
+func (s *ArxivSource) Name() string {
+	return s.SourceName
+}
@@ -0,0 +1,59 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+func TestArxivSource_Fetch(t *testing.T) {
+	response, err := os.ReadFile("testdata/arxiv.xml")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, `(cat:cs.LG OR cat:stat.ML) AND (all:"mixture of experts")`, r.URL.Query().Get("search_query"))
+		assert.Equal(t, "submittedDate", r.URL.Query().Get("sortBy"))
+		w.Header().Set("Content-Type", "application/atom+xml")
+		_, _ = w.Write(response)
+	}))
+	defer server.Close()
+
+	config, err := json.Marshal(ArxivConfig{
+		Categories: []string{"cs.LG", "stat.ML"},
+		Query:      "mixture of experts",
+		BaseURL:    server.URL,
+	})
+	require.NoError(t, err)
+
+	src, err := newArxivSource(model.Source{ID: 1, Name: "arXiv cs.LG", Kind: model.SourceKindArxiv, Config: config}, nil)
+	require.NoError(t, err)
+
+	items, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	paper := items[0]
+	assert.Equal(t, "Sparse Mixtures of Experts at Scale", paper.Title)
+	assert.Equal(t, "http://arxiv.org/abs/2506.01234", paper.Link)
+	assert.Equal(t, []string{"Ada Lovelace", "Alan Turing", "Grace Hopper", "Claude Shannon"}, paper.Authors)
+	assert.Equal(t, "cs.LG", paper.PrimaryCategory)
+	assert.Equal(t, []string{"cs.LG", "cs.AI"}, paper.Categories)
+	assert.Equal(t, "We study routing in sparse mixture-of-experts models and show that load balancing matters less than expected.", paper.Summary)
+
+	assert.Equal(t, "stat.ML", items[1].PrimaryCategory)
+}
+
+func TestValidateArxivConfig(t *testing.T) {
+	assert.NoError(t, validateArxivConfig(json.RawMessage(`{"categories":["cs.CL"]}`)))
+	assert.NoError(t, validateArxivConfig(json.RawMessage(`{"query":"ti:diffusion"}`)))
+	assert.Error(t, validateArxivConfig(json.RawMessage(`{}`)), "categories or query is required")
+	assert.Error(t, validateArxivConfig(json.RawMessage(`{"categories":["cs LG"]}`)))
+}
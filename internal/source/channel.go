@@ -0,0 +1,151 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+// ChannelSource stands for a Telegram channel the bot is an admin of. The
+// channel pushes its posts to the bot, so there is nothing to fetch.
+type ChannelSource struct {
+	SourceId   int64
+	SourceName string
+}
+
+func newChannelSource(m model.Source, _ *ratelimit.Limiter) (Source, error) {
+	if _, err := decodeConfig[struct{}](m.Config); err != nil {
+		return nil, err
+	}
+
+	return &ChannelSource{
+		SourceId:   m.ID,
+		SourceName: m.Name,
+	}, nil
+}
+
+func validateChannelConfig(config json.RawMessage) error {
+	_, err := decodeConfig[struct{}](config)
+	return err
+}
+
+func (s *ChannelSource) Fetch(context.Context) ([]model.Item, error) {
+	return nil, nil
+}
+
+func (s *ChannelSource) ID() int64 {
+	return s.SourceId
+}
+
+func (s *ChannelSource) Name() string {
+	return s.SourceName
+}
+
+// ChannelPostItem turns a channel post into an item. Posts without text or
+// caption are skipped.
+func ChannelPostItem(post *tgbotapi.Message, sourceName string) (model.Item, bool) {
+	if post == nil || post.Chat == nil {
+		return model.Item{}, false
+	}
+
+	text := strings.TrimSpace(messageText(post))
+	if text == "" {
+		return model.Item{}, false
+	}
+
+	permalink := channelPostPermalink(post)
+
+	link := MessageLink(post)
+	if link == "" {
+		link = permalink
+	}
+
+	firstLine, _, _ := strings.Cut(text, "\n")
+
+	return model.Item{
+		Title:        shortTitle(firstLine),
+		Link:         link,
+		CommentsLink: permalink,
+		Date:         post.Time().UTC(),
+		Summary:      text,
+		SourceName:   sourceName,
+	}, true
+}
+
+// channelPostPermalink links to the post by the channel username, or by the
+// internal chat ID for private channels.
+func channelPostPermalink(post *tgbotapi.Message) string {
+	if post.Chat.UserName != "" {
+		return fmt.Sprintf("https://t.me/%s/%d", post.Chat.UserName, post.MessageID)
+	}
+
+	chatID := strings.TrimPrefix(strconv.FormatInt(post.Chat.ID, 10), "-100")
+	return fmt.Sprintf("https://t.me/c/%s/%d", chatID, post.MessageID)
+}
+
+// MessageLink returns the first outbound link of a message's text or
+// caption, or an empty string if it has none.
+func MessageLink(msg *tgbotapi.Message) string {
+	if msg.Text != "" {
+		return firstEntityLink(msg.Text, msg.Entities)
+	}
+
+	return firstEntityLink(msg.Caption, msg.CaptionEntities)
+}
+
+func messageText(msg *tgbotapi.Message) string {
+	if msg.Text != "" {
+		return msg.Text
+	}
+
+	return msg.Caption
+}
+
+// firstEntityLink returns the first outbound link of the post. Entity
+// offsets count UTF-16 code units.
+func firstEntityLink(text string, entities []tgbotapi.MessageEntity) string {
+	encoded := utf16.Encode([]rune(text))
+
+	for _, entity := range entities {
+		var link string
+
+		switch entity.Type {
+		case "text_link":
+			link = entity.URL
+		case "url":
+			if entity.Offset < 0 || entity.Offset+entity.Length > len(encoded) {
+				continue
+			}
+			link = string(utf16.Decode(encoded[entity.Offset : entity.Offset+entity.Length]))
+			if !strings.Contains(link, "://") {
+				link = "https://" + link
+			}
+		default:
+			continue
+		}
+
+		if !isTelegramLink(link) {
+			return link
+		}
+	}
+
+	return ""
+}
+
+func isTelegramLink(link string) bool {
+	for _, prefix := range []string{"https://t.me/", "http://t.me/", "https://telegram.me/", "tg://"} {
+		if strings.HasPrefix(strings.ToLower(link), prefix) {
+			return true
+		}
+	}
+
+	return false
+}
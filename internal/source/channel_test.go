@@ -0,0 +1,54 @@
+package source
+
+import (
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelPostItem(t *testing.T) {
+	text := "Новина дня 🚀\nДеталі: example.com/news та https://t.me/other/5"
+
+	post := &tgbotapi.Message{
+		MessageID: 42,
+		Chat:      &tgbotapi.Chat{ID: -1001234567890, Type: "channel", UserName: "ai_news"},
+		Date:      int(time.Date(2025, 6, 6, 12, 0, 0, 0, time.UTC).Unix()),
+		Text:      text,
+		Entities: []tgbotapi.MessageEntity{
+			{Type: "url", Offset: 22, Length: 16},
+			{Type: "url", Offset: 42, Length: 20},
+		},
+	}
+
+	item, ok := ChannelPostItem(post, "AI News")
+	require.True(t, ok)
+
+	assert.Equal(t, "Новина дня 🚀", item.Title)
+	assert.Equal(t, "https://example.com/news", item.Link, "offsets count UTF-16 code units")
+	assert.Equal(t, "https://t.me/ai_news/42", item.CommentsLink)
+	assert.Equal(t, text, item.Summary)
+	assert.Equal(t, time.Date(2025, 6, 6, 12, 0, 0, 0, time.UTC), item.Date)
+}
+
+func TestChannelPostItem_PrivateChannelCaption(t *testing.T) {
+	post := &tgbotapi.Message{
+		MessageID: 7,
+		Chat:      &tgbotapi.Chat{ID: -1009876543210, Type: "channel"},
+		Caption:   "Slides from the talk",
+		CaptionEntities: []tgbotapi.MessageEntity{
+			{Type: "text_link", Offset: 0, Length: 6, URL: "https://example.com/slides.pdf"},
+		},
+	}
+
+	item, ok := ChannelPostItem(post, "Private")
+	require.True(t, ok)
+
+	assert.Equal(t, "https://example.com/slides.pdf", item.Link)
+	assert.Equal(t, "https://t.me/c/9876543210/7", item.CommentsLink)
+
+	_, ok = ChannelPostItem(&tgbotapi.Message{MessageID: 8, Chat: post.Chat}, "Private")
+	assert.False(t, ok, "posts without text are skipped")
+}
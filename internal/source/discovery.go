@@ -0,0 +1,146 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+// feedLinkTypes are the <link rel="alternate"> types that point to feeds.
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/json":      true,
+}
+
+var errNoFeed = errors.New("no feed found")
+
+// Discovery is the feed found at or linked from a URL.
+type Discovery struct {
+	FeedURL string
+	Kind    string
+	Title   string
+}
+
+var discoveryClient = &http.Client{
+	Timeout: 30 * time.Second,
+}
+
+// Discover checks that the URL is a feed, or returns the first working feed
+// the HTML page links to.
+func Discover(ctx context.Context, pageURL string) (Discovery, error) {
+	body, contentType, err := fetchBody(ctx, discoveryClient, pageURL,
+		"application/rss+xml, application/atom+xml, application/feed+json, application/xml;q=0.9, text/html;q=0.8")
+	if err != nil {
+		return Discovery{}, fmt.Errorf("failed to load %s: %w", pageURL, err)
+	}
+
+	if discovery, err := parseFeedBody(body, contentType); err == nil {
+		discovery.FeedURL = pageURL
+		return discovery, nil
+	}
+
+	links, err := alternateFeedLinks(body, pageURL)
+	if err != nil || len(links) == 0 {
+		return Discovery{}, fmt.Errorf("%w at %s: it is neither a feed nor a page that links to one", errNoFeed, pageURL)
+	}
+
+	var lastErr error
+	for _, link := range links {
+		body, contentType, err := fetchBody(ctx, discoveryClient, link, "application/rss+xml, application/atom+xml, application/feed+json")
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		discovery, err := parseFeedBody(body, contentType)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		discovery.FeedURL = link
+		return discovery, nil
+	}
+
+	return Discovery{}, fmt.Errorf("%w: the feeds linked from %s do not work: %v", errNoFeed, pageURL, lastErr)
+}
+
+// parseFeedBody recognizes JSON feeds and the formats gofeed reads.
+func parseFeedBody(body []byte, contentType string) (Discovery, error) {
+	var jf jsonFeed
+	if isJSONFeedContentType(contentType) || bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+		if err := json.Unmarshal(body, &jf); err == nil && strings.HasPrefix(jf.Version, "https://jsonfeed.org/version/") {
+			return Discovery{Kind: model.SourceKindJSONFeed, Title: jf.Title}, nil
+		}
+	}
+
+	feed, err := gofeed.NewParser().Parse(bytes.NewReader(body))
+	if err != nil {
+		return Discovery{}, err
+	}
+
+	return Discovery{Kind: model.SourceKindRSS, Title: feed.Title}, nil
+}
+
+// alternateFeedLinks returns the absolute URLs of the feeds a page links to,
+// in page order.
+func alternateFeedLinks(body []byte, pageURL string) ([]string, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if b, err := base.Parse(href); err == nil {
+			base = b
+		}
+	}
+
+	var links []string
+	doc.Find(`link[rel~="alternate"][href]`).Each(func(_ int, sel *goquery.Selection) {
+		linkType, _ := sel.Attr("type")
+		mediaType, _, err := mime.ParseMediaType(linkType)
+		if err != nil {
+			return
+		}
+
+		if !feedLinkTypes[mediaType] {
+			return
+		}
+
+		href, _ := sel.Attr("href")
+		if link, err := base.Parse(strings.TrimSpace(href)); err == nil {
+			links = append(links, link.String())
+		}
+	})
+
+	return links, nil
+}
+
+// NormalizeFeedURL makes feed URLs that differ only in case, scheme or a
+// trailing slash compare equal.
+func NormalizeFeedURL(u string) string {
+	u = strings.ToLower(strings.TrimSpace(u))
+	u = strings.TrimPrefix(u, "https://")
+	u = strings.TrimPrefix(u, "http://")
+	return strings.TrimSuffix(u, "/")
+}
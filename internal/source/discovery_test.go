@@ -0,0 +1,63 @@
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+const discoveryFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>Lab Blog</title>
+<item><title>Post</title><link>https://lab.example.com/post</link></item>
+</channel></rss>`
+
+func TestDiscover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><head>
+				<link rel="stylesheet" href="/style.css">
+				<link rel="alternate" type="application/rss+xml" title="Broken" href="/missing.xml">
+				<link rel="alternate" type="application/rss+xml; charset=utf-8" title="Posts" href="feed.xml">
+			</head><body>Hello</body></html>`))
+		case "/feed.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			_, _ = w.Write([]byte(discoveryFeed))
+		case "/feed.json":
+			w.Header().Set("Content-Type", "application/feed+json")
+			_, _ = w.Write([]byte(`{"version":"https://jsonfeed.org/version/1.1","title":"Microblog","items":[]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("page links to a feed", func(t *testing.T) {
+		discovery, err := Discover(context.Background(), server.URL+"/")
+		require.NoError(t, err)
+		assert.Equal(t, Discovery{FeedURL: server.URL + "/feed.xml", Kind: model.SourceKindRSS, Title: "Lab Blog"}, discovery)
+	})
+
+	t.Run("feed URL", func(t *testing.T) {
+		discovery, err := Discover(context.Background(), server.URL+"/feed.json")
+		require.NoError(t, err)
+		assert.Equal(t, Discovery{FeedURL: server.URL + "/feed.json", Kind: model.SourceKindJSONFeed, Title: "Microblog"}, discovery)
+	})
+
+	t.Run("no feed", func(t *testing.T) {
+		_, err := Discover(context.Background(), server.URL+"/missing.xml")
+		assert.Error(t, err)
+	})
+}
+
+func TestNormalizeFeedURL(t *testing.T) {
+	assert.Equal(t, NormalizeFeedURL("https://Example.com/feed/"), NormalizeFeedURL("http://example.com/feed"))
+	assert.NotEqual(t, NormalizeFeedURL("https://example.com/feed"), NormalizeFeedURL("https://example.com/feed.xml"))
+}
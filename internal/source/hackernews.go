@@ -0,0 +1,299 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+const (
+	defaultHNBaseURL    = "https://hacker-news.firebaseio.com/v0"
+	hnDiscussionURL     = "https://news.ycombinator.com/item?id=%d"
+	defaultHNLimit      = 30
+	hnLookupConcurrency = 5
+	hnStoryType         = "story"
+
+	hnListTop  = "top"
+	hnListNew  = "new"
+	hnListBest = "best"
+)
+
+// HackerNewsConfig selects a story list and the thresholds a story must
+// pass to become an item.
+type HackerNewsConfig struct {
+	// List is one of "top", "new" or "best". Defaults to "top".
+	List        string `json:"list"`
+	MinScore    int    `json:"min_score"`
+	MinComments int    `json:"min_comments"`
+	// Keywords keep only stories whose title contains one of them.
+	Keywords []string `json:"keywords"`
+	// Limit is how many stories from the head of the list are looked up.
+	Limit   int    `json:"limit"`
+	BaseURL string `json:"base_url"`
+}
+
+func (c HackerNewsConfig) validate() error {
+	switch c.List {
+	case "", hnListTop, hnListNew, hnListBest:
+	default:
+		return fmt.Errorf("hackernews source config: unknown list %q, expected top, new or best", c.List)
+	}
+
+	if c.MinScore < 0 || c.MinComments < 0 || c.Limit < 0 {
+		return fmt.Errorf("hackernews source config: thresholds and limit must not be negative")
+	}
+
+	return nil
+}
+
+// HackerNewsSource reads stories from the Hacker News Firebase API.
+type HackerNewsSource struct {
+	SourceId   int64
+	SourceName string
+	Config     HackerNewsConfig
+	client     *http.Client
+}
+
+func newHackerNewsSource(m model.Source, limiter *ratelimit.Limiter) (Source, error) {
+	config, err := decodeConfig[HackerNewsConfig](m.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	if config.List == "" {
+		config.List = hnListTop
+	}
+	if config.Limit == 0 {
+		config.Limit = defaultHNLimit
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = defaultHNBaseURL
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+
+	return &HackerNewsSource{
+		SourceId:   m.ID,
+		SourceName: m.Name,
+		Config:     config,
+		client:     newHTTPClient(m, limiter, config.BaseURL),
+	}, nil
+}
+
+func validateHackerNewsConfig(config json.RawMessage) error {
+	hnConfig, err := decodeConfig[HackerNewsConfig](config)
+	if err != nil {
+		return err
+	}
+
+	return hnConfig.validate()
+}
+
+type hnStory struct {
+	ID          int64  `json:"id"`
+	Type        string `json:"type"`
+	By          string `json:"by"`
+	Time        int64  `json:"time"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Text        string `json:"text"`
+	Score       int    `json:"score"`
+	Descendants int    `json:"descendants"`
+	Dead        bool   `json:"dead"`
+	Deleted     bool   `json:"deleted"`
+}
+
+func (s *HackerNewsSource) Fetch(ctx context.Context) ([]model.Item, error) {
+	var ids []int64
+	if err := s.getJSON(ctx, fmt.Sprintf("%s/%sstories.json", s.Config.BaseURL, s.Config.List), &ids); err != nil {
+		return nil, fmt.Errorf("failed to load %s stories: %w", s.Config.List, err)
+	}
+
+	if len(ids) > s.Config.Limit {
+		ids = ids[:s.Config.Limit]
+	}
+
+	stories, err := s.lookupStories(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stories: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var items []model.Item
+	for _, story := range stories {
+		if story == nil || !s.qualifies(*story) {
+			continue
+		}
+
+		items = append(items, s.storyToItem(*story))
+	}
+
+	return items, nil
+}
+
+// lookupStories fetches the stories in the order of ids. Stories that fail
+// to load are left nil.
+func (s *HackerNewsSource) lookupStories(ctx context.Context, ids []int64) ([]*hnStory, error) {
+	var (
+		stories = make([]*hnStory, len(ids))
+		errs    = make([]error, len(ids))
+		sem     = make(chan struct{}, hnLookupConcurrency)
+		wg      sync.WaitGroup
+	)
+
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id int64) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			var story hnStory
+			if err := s.getJSON(ctx, fmt.Sprintf("%s/item/%d.json", s.Config.BaseURL, id), &story); err != nil {
+				errs[i] = err
+				return
+			}
+
+			stories[i] = &story
+		}(i, id)
+	}
+
+	wg.Wait()
+
+	return stories, lookupError(errs)
+}
+
+// lookupError returns the first throttled or temporary lookup error, or an
+// error when every lookup failed.
+func lookupError(errs []error) error {
+	var (
+		status error
+		failed int
+	)
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		failed++
+
+		if _, ok := ratelimit.ThrottledUntil(err); ok {
+			return err
+		}
+
+		var statusErr *ratelimit.StatusError
+		if status == nil && errors.As(err, &statusErr) && statusErr.Temporary() {
+			status = err
+		}
+	}
+
+	if status != nil {
+		return status
+	}
+
+	if failed > 0 && failed == len(errs) {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func (s *HackerNewsSource) qualifies(story hnStory) bool {
+	if story.Type != hnStoryType || story.Dead || story.Deleted || story.Title == "" {
+		return false
+	}
+
+	if story.Score < s.Config.MinScore || story.Descendants < s.Config.MinComments {
+		return false
+	}
+
+	if len(s.Config.Keywords) == 0 {
+		return true
+	}
+
+	title := strings.ToLower(story.Title)
+	for _, keyword := range s.Config.Keywords {
+		if strings.Contains(title, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *HackerNewsSource) storyToItem(story hnStory) model.Item {
+	discussion := fmt.Sprintf(hnDiscussionURL, story.ID)
+
+	link := story.URL
+	if link == "" {
+		// Ask HN and similar text posts only live on HN.
+		link = discussion
+	}
+
+	var authors []string
+	if story.By != "" {
+		authors = []string{story.By}
+	}
+
+	return model.Item{
+		Title:        story.Title,
+		Authors:      authors,
+		Link:         link,
+		CommentsLink: discussion,
+		Date:         time.Unix(story.Time, 0).UTC(),
+		Summary:      hnText(story.Text),
+		SourceName:   s.SourceName,
+	}
+}
+
+func (s *HackerNewsSource) getJSON(ctx context.Context, url string, v any) error {
+	body, _, err := fetchBody(ctx, s.client, url, "application/json")
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+func (s *HackerNewsSource) ID() int64 {
+	return s.SourceId
+}
+
+func (s *HackerNewsSource) Name() string {
+	return s.SourceName
+}
+
+// hnText turns the HTML of a text post into plain text. Paragraphs are
+// separated by bare <p> tags.
+func hnText(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(strings.ReplaceAll(text, "<p>", "\n\n<p>")))
+	if err != nil {
+		return text
+	}
+
+	return strings.TrimSpace(doc.Text())
+}
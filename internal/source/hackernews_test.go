@@ -0,0 +1,108 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+func newHNStandIn(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	stories := map[string]string{
+		"/item/1.json": `{"id":1,"type":"story","by":"ada","time":1748937600,"title":"New LLM tops the benchmarks","url":"https://example.com/llm","score":420,"descendants":180}`,
+		"/item/2.json": `{"id":2,"type":"story","by":"bob","time":1748937600,"title":"Ask HN: How do you evaluate LLM agents?","text":"Curious what people use.<p>Evals &amp; <a href=\"https://example.com/evals\">benchmarks</a>?","score":150,"descendants":90}`,
+		"/item/3.json": `{"id":3,"type":"story","by":"eve","time":1748937600,"title":"LLM with low score","url":"https://example.com/low","score":5,"descendants":1}`,
+		"/item/4.json": `{"id":4,"type":"story","by":"mallory","time":1748937600,"title":"Show HN: A faster bicycle","url":"https://example.com/bike","score":500,"descendants":200}`,
+		"/item/5.json": `{"id":5,"type":"job","time":1748937600,"title":"LLM startup is hiring","url":"https://example.com/job"}`,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/topstories.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[1,2,3,4,5,6]`))
+	})
+	mux.HandleFunc("/item/", func(w http.ResponseWriter, r *http.Request) {
+		story, ok := stories[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(story))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestHackerNewsSource_Fetch(t *testing.T) {
+	server := newHNStandIn(t)
+
+	config, err := json.Marshal(HackerNewsConfig{
+		MinScore:    100,
+		MinComments: 50,
+		Keywords:    []string{"llm"},
+		BaseURL:     server.URL,
+	})
+	require.NoError(t, err)
+
+	src, err := newHackerNewsSource(model.Source{ID: 1, Name: "HN", Kind: model.SourceKindHN, Config: config}, nil)
+	require.NoError(t, err)
+
+	items, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, "New LLM tops the benchmarks", items[0].Title)
+	assert.Equal(t, "https://example.com/llm", items[0].Link)
+	assert.Equal(t, fmt.Sprintf(hnDiscussionURL, 1), items[0].CommentsLink)
+	assert.Equal(t, []string{"ada"}, items[0].Authors)
+
+	assert.Equal(t, fmt.Sprintf(hnDiscussionURL, 2), items[1].Link, "text posts link to the discussion")
+	assert.Equal(t, "Curious what people use.\n\nEvals & benchmarks?", items[1].Summary)
+}
+
+func TestHackerNewsSource_FetchThrottled(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/topstories.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[1,2]`))
+	})
+	mux.HandleFunc("/item/1.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":1,"type":"story","title":"LLM","url":"https://example.com/llm","score":420,"descendants":180}`))
+	})
+	mux.HandleFunc("/item/2.json", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	config, err := json.Marshal(HackerNewsConfig{BaseURL: server.URL})
+	require.NoError(t, err)
+
+	src, err := newHackerNewsSource(model.Source{ID: 1, Name: "HN", Kind: model.SourceKindHN, Config: config}, nil)
+	require.NoError(t, err)
+
+	_, err = src.Fetch(context.Background())
+	require.Error(t, err)
+
+	var statusErr *ratelimit.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.True(t, statusErr.Throttled())
+}
+
+func TestValidateHackerNewsConfig(t *testing.T) {
+	assert.NoError(t, validateHackerNewsConfig(json.RawMessage(`{"list":"best","min_score":100}`)))
+	assert.Error(t, validateHackerNewsConfig(json.RawMessage(`{"list":"worst"}`)))
+	assert.Error(t, validateHackerNewsConfig(json.RawMessage(`{"min_score":-1}`)))
+}
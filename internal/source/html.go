@@ -0,0 +1,262 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+// HTMLConfig holds the CSS selectors used to pull items out of a listing
+// page. Title, Link, Date and Teaser are looked up inside each Item.
+type HTMLConfig struct {
+	Item   string `json:"item"`
+	Title  string `json:"title"`
+	Link   string `json:"link"`
+	Date   string `json:"date"`
+	Teaser string `json:"teaser"`
+	// DateFormats are Go time layouts tried before the built-in ones.
+	DateFormats []string `json:"date_formats"`
+}
+
+func (c HTMLConfig) validate() error {
+	if c.Item == "" {
+		return errors.New("html source config: item selector is required")
+	}
+	if c.Title == "" {
+		return errors.New("html source config: title selector is required")
+	}
+
+	for name, selector := range map[string]string{
+		"item":   c.Item,
+		"title":  c.Title,
+		"link":   c.Link,
+		"date":   c.Date,
+		"teaser": c.Teaser,
+	} {
+		if selector == "" {
+			continue
+		}
+
+		if _, err := cascadia.Compile(selector); err != nil {
+			return fmt.Errorf("html source config: invalid %s selector %q: %w", name, selector, err)
+		}
+	}
+
+	return nil
+}
+
+// HTMLSource scrapes items from a page that has no feed.
+type HTMLSource struct {
+	URL        string
+	SourceId   int64
+	SourceName string
+	Config     HTMLConfig
+	client     *http.Client
+}
+
+func newHTMLSource(m model.Source, limiter *ratelimit.Limiter) (Source, error) {
+	config, err := decodeConfig[HTMLConfig](m.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	return &HTMLSource{
+		URL:        m.FeedURL,
+		SourceId:   m.ID,
+		SourceName: m.Name,
+		Config:     config,
+		client:     newHTTPClient(m, limiter),
+	}, nil
+}
+
+func validateHTMLConfig(config json.RawMessage) error {
+	htmlConfig, err := decodeConfig[HTMLConfig](config)
+	if err != nil {
+		return err
+	}
+
+	return htmlConfig.validate()
+}
+
+func (s *HTMLSource) Fetch(ctx context.Context) ([]model.Item, error) {
+	body, _, err := fetchBody(ctx, s.client, s.URL, "text/html, application/xhtml+xml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load page %s: %w", s.URL, err)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse page %s: %w", s.URL, err)
+	}
+
+	baseURL, err := url.Parse(s.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page url %s: %w", s.URL, err)
+	}
+
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if base, err := baseURL.Parse(href); err == nil {
+			baseURL = base
+		}
+	}
+
+	var items []model.Item
+	doc.Find(s.Config.Item).Each(func(_ int, sel *goquery.Selection) {
+		item, ok := s.parseItem(sel, baseURL)
+		if ok {
+			items = append(items, item)
+		}
+	})
+
+	return items, nil
+}
+
+func (s *HTMLSource) parseItem(sel *goquery.Selection, baseURL *url.URL) (model.Item, bool) {
+	titleSel := sel.Find(s.Config.Title).First()
+
+	title := collapseSpaces(titleSel.Text())
+	if title == "" {
+		return model.Item{}, false
+	}
+
+	var href string
+	if s.Config.Link != "" {
+		href = linkHref(sel.Find(s.Config.Link).First())
+	} else {
+		href = linkHref(titleSel)
+		if href == "" {
+			href = linkHref(sel)
+		}
+	}
+
+	if href == "" {
+		return model.Item{}, false
+	}
+
+	link, err := baseURL.Parse(href)
+	if err != nil {
+		return model.Item{}, false
+	}
+
+	item := model.Item{
+		Title:      title,
+		Link:       link.String(),
+		SourceName: s.SourceName,
+	}
+
+	if s.Config.Date != "" {
+		dateSel := sel.Find(s.Config.Date).First()
+
+		value, ok := dateSel.Attr("datetime")
+		if !ok {
+			value = dateSel.Text()
+		}
+
+		item.Date = parseDate(collapseSpaces(value), s.Config.DateFormats)
+	}
+
+	if s.Config.Teaser != "" {
+		item.Summary = collapseSpaces(sel.Find(s.Config.Teaser).First().Text())
+	}
+
+	return item, true
+}
+
+// linkHref returns the href of the selection if it is a link, or of the
+// closest link around it, or of the first link inside it.
+func linkHref(sel *goquery.Selection) string {
+	if sel.Length() == 0 {
+		return ""
+	}
+
+	if href, ok := sel.Attr("href"); ok {
+		return strings.TrimSpace(href)
+	}
+
+	if href, ok := sel.Closest("a[href]").Attr("href"); ok {
+		return strings.TrimSpace(href)
+	}
+
+	if href, ok := sel.Find("a[href]").First().Attr("href"); ok {
+		return strings.TrimSpace(href)
+	}
+
+	return ""
+}
+
+var htmlDateFormats = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+	"02.01.2006",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"Jan. 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"Monday, January 2, 2006",
+}
+
+// parseDate tries the custom layouts and then the built-in ones. Unparsable
+// dates are left zero.
+func parseDate(value string, customFormats []string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+
+	for _, formats := range [][]string{customFormats, htmlDateFormats} {
+		for _, format := range formats {
+			if date, err := time.Parse(format, value); err == nil {
+				return date
+			}
+		}
+	}
+
+	return time.Time{}
+}
+
+func collapseSpaces(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+const maxShortTitleLength = 100
+
+// shortTitle makes a title out of the start of a text, for items that have
+// no title of their own.
+func shortTitle(text string) string {
+	text = collapseSpaces(text)
+	if runes := []rune(text); len(runes) > maxShortTitleLength {
+		text = string(runes[:maxShortTitleLength]) + "…"
+	}
+
+	return text
+}
+
+func (s *HTMLSource) ID() int64 {
+	return s.SourceId
+}
+
+func (s *HTMLSource) Name() string {
+	return s.SourceName
+}
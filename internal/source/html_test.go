@@ -0,0 +1,44 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+func TestHTMLSource_Fetch(t *testing.T) {
+	server := serveFixture(t, "testdata/blog.html", "text/html; charset=utf-8")
+
+	src, err := newHTMLSource(model.Source{
+		ID:      1,
+		Name:    "Lab blog",
+		FeedURL: server.URL + "/blog/",
+		Config:  json.RawMessage(`{"item":"article.post","title":".post-title","date":"time, .date","teaser":".teaser"}`),
+	}, nil)
+	require.NoError(t, err)
+
+	items, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, "Revisiting scaling laws", items[0].Title)
+	assert.Equal(t, server.URL+"/blog/scaling-laws", items[0].Link, "relative links resolve against the page URL")
+	assert.True(t, items[0].Date.Equal(time.Date(2025, 6, 3, 8, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "We re-run the classic experiments at a larger budget.", items[0].Summary)
+
+	assert.Equal(t, "Interpretability update", items[1].Title)
+	assert.Equal(t, "https://lab.example.com/blog/interp", items[1].Link, "links fall back to the first link in the item")
+	assert.True(t, items[1].Date.Equal(time.Date(2025, 5, 28, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestValidateHTMLConfig(t *testing.T) {
+	assert.NoError(t, validateHTMLConfig(json.RawMessage(`{"item":"li","title":"a"}`)))
+	assert.Error(t, validateHTMLConfig(json.RawMessage(`{"title":"a"}`)), "item selector is required")
+	assert.Error(t, validateHTMLConfig(json.RawMessage(`{"item":"li","title":"a[["}`)), "selectors must compile")
+}
@@ -0,0 +1,87 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"neuro_scout_bot_v1/internal/httpclient"
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+// maxBodySize bounds how much of a response is read.
+const maxBodySize = 10 << 20
+
+// defaultBaseURLs are the API URLs of the kinds that fetch from an API
+// rather than from the feed URL.
+var defaultBaseURLs = map[string]string{
+	model.SourceKindArxiv:    defaultArxivBaseURL,
+	model.SourceKindHN:       defaultHNBaseURL,
+	model.SourceKindReddit:   defaultRedditBaseURL,
+	model.SourceKindTelegram: defaultTelegramBaseURL,
+}
+
+// HTTPHosts returns the hosts the source's HTTP secrets may be sent to: the
+// feed's host and, for kinds that fetch from an API, the API's host.
+func HTTPHosts(m model.Source) []string {
+	hosts := []string{ratelimit.HostOf(m.FeedURL)}
+
+	baseURL := defaultBaseURLs[m.Kind]
+
+	var config struct {
+		BaseURL string `json:"base_url"`
+	}
+	if len(m.Config) > 0 && json.Unmarshal(m.Config, &config) == nil && config.BaseURL != "" {
+		baseURL = config.BaseURL
+	}
+
+	if baseURL != "" {
+		hosts = append(hosts, ratelimit.HostOf(baseURL))
+	}
+
+	return hosts
+}
+
+// newHTTPClient returns the client of a source, whose secrets go to the
+// feed's host and the given API hosts only.
+func newHTTPClient(m model.Source, limiter *ratelimit.Limiter, apiURLs ...string) *http.Client {
+	hosts := []string{ratelimit.HostOf(m.FeedURL)}
+	for _, apiURL := range apiURLs {
+		hosts = append(hosts, ratelimit.HostOf(apiURL))
+	}
+
+	return httpclient.New(m.HTTP, limiter, hosts...)
+}
+
+// fetchBody downloads url and returns the body along with the response
+// content type. Any non-2xx status is a *ratelimit.StatusError.
+func fetchBody(ctx context.Context, client *http.Client, url, accept string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", httpclient.UserAgent)
+	req.Header.Set("Accept", accept)
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if err := ratelimit.CheckResponse(resp); err != nil {
+		return nil, "", err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return body, resp.Header.Get("Content-Type"), nil
+}
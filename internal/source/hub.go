@@ -0,0 +1,115 @@
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// linkHeaderPart matches one link of an HTTP Link header, such as
+// <https://hub.example.com/>; rel="hub".
+var linkHeaderPart = regexp.MustCompile(`<([^>]*)>\s*((?:;[^,<]*)*)`)
+
+var linkRel = regexp.MustCompile(`(?i);\s*rel\s*=\s*"?([^";]*)"?`)
+
+// hubLinks returns the WebSub hub and topic URLs a feed advertises in the
+// Link header or in the feed.
+func hubLinks(header http.Header, body []byte) (hub, self string) {
+	for _, value := range header.Values("Link") {
+		for _, match := range linkHeaderPart.FindAllStringSubmatch(value, -1) {
+			rel := linkRel.FindStringSubmatch(match[2])
+			if rel == nil {
+				continue
+			}
+
+			for _, r := range strings.Fields(strings.ToLower(rel[1])) {
+				if r == "hub" && hub == "" {
+					hub = strings.TrimSpace(match[1])
+				}
+				if r == "self" && self == "" {
+					self = strings.TrimSpace(match[1])
+				}
+			}
+		}
+	}
+
+	if hub == "" {
+		if bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+			hub, self = jsonFeedHub(body)
+		} else {
+			hub, self = xmlFeedHub(body)
+		}
+	}
+
+	if hub == "" {
+		return "", ""
+	}
+
+	return hub, self
+}
+
+// xmlFeedHub reads the rel="hub" and rel="self" links of an RSS channel or
+// an Atom feed, stopping at the first item or entry.
+func xmlFeedHub(body []byte) (hub, self string) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return hub, self
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "item", "entry":
+			return hub, self
+		case "link":
+			var rel, href string
+			for _, attr := range start.Attr {
+				switch attr.Name.Local {
+				case "rel":
+					rel = strings.ToLower(strings.TrimSpace(attr.Value))
+				case "href":
+					href = strings.TrimSpace(attr.Value)
+				}
+			}
+
+			if rel == "hub" && hub == "" {
+				hub = href
+			}
+			if rel == "self" && self == "" {
+				self = href
+			}
+		}
+	}
+}
+
+func jsonFeedHub(body []byte) (hub, self string) {
+	var feed struct {
+		FeedURL string `json:"feed_url"`
+		Hubs    []struct {
+			Type string `json:"type"`
+			URL  string `json:"url"`
+		} `json:"hubs"`
+	}
+
+	if err := json.Unmarshal(body, &feed); err != nil {
+		return "", ""
+	}
+
+	for _, h := range feed.Hubs {
+		if strings.EqualFold(h.Type, "websub") && h.URL != "" {
+			return h.URL, feed.FeedURL
+		}
+	}
+
+	return "", ""
+}
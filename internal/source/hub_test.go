@@ -0,0 +1,50 @@
+package source
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHubLinks(t *testing.T) {
+	const atom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Blog</title>
+  <link rel="alternate" href="https://example.com/"/>
+  <link rel="self" href="https://example.com/feed.atom"/>
+  <link rel="hub" href="https://hub.example.com/"/>
+  <entry><link rel="hub" href="https://ignored.example.com/"/></entry>
+</feed>`
+
+	const rss = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+  <channel>
+    <link>https://example.com/</link>
+    <atom:link rel="hub" href="https://pubsubhubbub.appspot.com/"/>
+    <atom:link rel="self" href="https://example.com/rss"/>
+  </channel>
+</rss>`
+
+	hub, self := hubLinks(http.Header{}, []byte(atom))
+	assert.Equal(t, "https://hub.example.com/", hub)
+	assert.Equal(t, "https://example.com/feed.atom", self)
+
+	hub, self = hubLinks(http.Header{}, []byte(rss))
+	assert.Equal(t, "https://pubsubhubbub.appspot.com/", hub)
+	assert.Equal(t, "https://example.com/rss", self)
+
+	header := http.Header{}
+	header.Add("Link", `<https://example.com/topic>; rel="self", <https://hub.example.net/>; rel="hub"`)
+	hub, self = hubLinks(header, []byte(rss))
+	assert.Equal(t, "https://hub.example.net/", hub, "the Link header wins")
+	assert.Equal(t, "https://example.com/topic", self)
+
+	hub, self = hubLinks(http.Header{}, []byte(`{"feed_url":"https://example.com/feed.json","hubs":[{"type":"WebSub","url":"https://hub.example.org/"}]}`))
+	assert.Equal(t, "https://hub.example.org/", hub)
+	assert.Equal(t, "https://example.com/feed.json", self)
+
+	hub, self = hubLinks(http.Header{}, []byte(`<rss><channel><link>https://example.com/</link></channel></rss>`))
+	assert.Empty(t, hub)
+	assert.Empty(t, self)
+}
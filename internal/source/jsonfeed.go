@@ -0,0 +1,200 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+// JSONFeedSource reads feeds in the JSON Feed format (https://jsonfeed.org).
+type JSONFeedSource struct {
+	URL        string
+	SourceId   int64
+	SourceName string
+	client     *http.Client
+}
+
+func NewJSONFeedSourceFromModel(m model.Source, limiter *ratelimit.Limiter) *JSONFeedSource {
+	return &JSONFeedSource{
+		URL:        m.FeedURL,
+		SourceId:   m.ID,
+		SourceName: m.Name,
+		client:     newHTTPClient(m, limiter),
+	}
+}
+
+func newJSONFeedSource(m model.Source, limiter *ratelimit.Limiter) (Source, error) {
+	if _, err := decodeConfig[struct{}](m.Config); err != nil {
+		return nil, err
+	}
+
+	return NewJSONFeedSourceFromModel(m, limiter), nil
+}
+
+func validateJSONFeedConfig(config json.RawMessage) error {
+	_, err := decodeConfig[struct{}](config)
+	return err
+}
+
+func (s *JSONFeedSource) Fetch(ctx context.Context) ([]model.Item, error) {
+	body, _, err := fetchBody(ctx, s.client, s.URL, "application/feed+json, application/json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feed from %s: %w", s.URL, err)
+	}
+
+	return parseJSONFeed(body, s.SourceName)
+}
+
+func (s *JSONFeedSource) ID() int64 {
+	return s.SourceId
+}
+
+func (s *JSONFeedSource) Name() string {
+	return s.SourceName
+}
+
+type jsonFeed struct {
+	Version string         `json:"version"`
+	Title   string         `json:"title"`
+	Items   []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	ExternalURL   string           `json:"external_url"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html"`
+	ContentText   string           `json:"content_text"`
+	Summary       string           `json:"summary"`
+	Image         string           `json:"image"`
+	BannerImage   string           `json:"banner_image"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified"`
+	Tags          []string         `json:"tags"`
+	Authors       []jsonFeedAuthor `json:"authors"`
+	// Author is the JSON Feed 1.0 field replaced by Authors in 1.1.
+	Author *jsonFeedAuthor `json:"author"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+func parseJSONFeed(body []byte, sourceName string) ([]model.Item, error) {
+	var feed jsonFeed
+	if err := json.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON feed: %w", err)
+	}
+
+	if !strings.HasPrefix(feed.Version, "https://jsonfeed.org/version/") {
+		return nil, fmt.Errorf("not a JSON feed: unexpected version %q", feed.Version)
+	}
+
+	items := make([]model.Item, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		link := item.URL
+		if link == "" {
+			link = item.ExternalURL
+		}
+
+		if link == "" {
+			continue
+		}
+
+		summary := item.Summary
+		if summary == "" {
+			summary = item.ContentHTML
+		}
+		if summary == "" {
+			summary = item.ContentText
+		}
+
+		image := item.Image
+		if image == "" {
+			image = item.BannerImage
+		}
+
+		content := item.ContentHTML
+		if content == "" {
+			content = item.ContentText
+		}
+
+		var updated time.Time
+		if date, err := time.Parse(time.RFC3339, item.DateModified); err == nil {
+			updated = date
+		}
+
+		items = append(items, model.Item{
+			Title:      jsonFeedItemTitle(item),
+			Categories: item.Tags,
+			Authors:    jsonFeedAuthors(item),
+			Link:       link,
+			GUID:       item.ID,
+			ImageURL:   image,
+			Date:       jsonFeedItemDate(item),
+			Updated:    updated,
+			Summary:    summary,
+			Content:    content,
+			SourceName: sourceName,
+		})
+	}
+
+	return items, nil
+}
+
+// jsonFeedItemTitle falls back to the start of the text for untitled items,
+// which are common in microblog feeds.
+func jsonFeedItemTitle(item jsonFeedItem) string {
+	if item.Title != "" {
+		return item.Title
+	}
+
+	text := item.ContentText
+	if text == "" {
+		text = item.Summary
+	}
+
+	return shortTitle(text)
+}
+
+func jsonFeedItemDate(item jsonFeedItem) time.Time {
+	for _, value := range []string{item.DatePublished, item.DateModified} {
+		if date, err := time.Parse(time.RFC3339, value); err == nil {
+			return date
+		}
+	}
+
+	return time.Time{}
+}
+
+func jsonFeedAuthors(item jsonFeedItem) []string {
+	var authors []string
+	for _, author := range item.Authors {
+		if author.Name != "" {
+			authors = append(authors, author.Name)
+		}
+	}
+
+	if len(authors) == 0 && item.Author != nil && item.Author.Name != "" {
+		authors = append(authors, item.Author.Name)
+	}
+
+	return authors
+}
+
+func isJSONFeedContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/feed+json"
+}
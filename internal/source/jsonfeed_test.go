@@ -0,0 +1,65 @@
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+func serveFixture(t *testing.T, path, contentType string) *httptest.Server {
+	t.Helper()
+
+	body, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestJSONFeedSource_Fetch(t *testing.T) {
+	server := serveFixture(t, "testdata/jsonfeed.json", "application/json")
+
+	source := NewJSONFeedSourceFromModel(model.Source{ID: 1, Name: "Notes", FeedURL: server.URL}, nil)
+
+	items, err := source.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	first := items[0]
+	assert.Equal(t, "Sparse attention, explained", first.Title)
+	assert.Equal(t, "https://notes.example.com/2025/06/sparse-attention", first.Link)
+	assert.Equal(t, "A short tour of sparse attention patterns.", first.Summary)
+	assert.Equal(t, []string{"attention", "transformers"}, first.Categories)
+	assert.Equal(t, []string{"Ada", "Grace"}, first.Authors)
+	assert.True(t, first.Date.Equal(time.Date(2025, 6, 2, 7, 30, 0, 0, time.UTC)))
+	assert.Equal(t, "Notes", first.SourceName)
+
+	second := items[1]
+	assert.Equal(t, "Just shipped a tiny tokenizer benchmark, numbers inside.", second.Title)
+	assert.Equal(t, []string{"Ada"}, second.Authors)
+	assert.True(t, second.Date.Equal(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestRSSSource_FetchDetectsJSONFeed(t *testing.T) {
+	server := serveFixture(t, "testdata/jsonfeed.json", "application/feed+json; charset=utf-8")
+
+	source := NewRSSSourceFromModel(model.Source{ID: 1, Name: "Notes", FeedURL: server.URL}, nil)
+
+	items, err := source.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "Sparse attention, explained", items[0].Title)
+}
@@ -0,0 +1,64 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"neuro_scout_bot_v1/internal/httpclient"
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+// PageMetadata is what a page says about itself in its head.
+type PageMetadata struct {
+	Title       string
+	Description string
+}
+
+// FetchPageMetadata loads a page through the limiter and reads its title and
+// description, preferring Open Graph and Twitter card tags over the plain ones.
+func FetchPageMetadata(ctx context.Context, limiter *ratelimit.Limiter, pageURL string) (PageMetadata, error) {
+	client := httpclient.New(model.HTTPOptions{}, limiter)
+
+	body, _, err := fetchBody(ctx, client, pageURL, "text/html, application/xhtml+xml")
+	if err != nil {
+		return PageMetadata{}, fmt.Errorf("failed to load page %s: %w", pageURL, err)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return PageMetadata{}, fmt.Errorf("failed to parse page %s: %w", pageURL, err)
+	}
+
+	return PageMetadata{
+		Title: firstNonEmpty(
+			metaContent(doc, `meta[property="og:title"]`),
+			metaContent(doc, `meta[name="twitter:title"]`),
+			collapseSpaces(doc.Find("title").First().Text()),
+			collapseSpaces(doc.Find("h1").First().Text()),
+		),
+		Description: firstNonEmpty(
+			metaContent(doc, `meta[property="og:description"]`),
+			metaContent(doc, `meta[name="twitter:description"]`),
+			metaContent(doc, `meta[name="description"]`),
+		),
+	}, nil
+}
+
+func metaContent(doc *goquery.Document, selector string) string {
+	content, _ := doc.Find(selector).First().Attr("content")
+	return collapseSpaces(content)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
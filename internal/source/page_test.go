@@ -0,0 +1,37 @@
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchPageMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.URL.Path {
+		case "/og":
+			_, _ = w.Write([]byte(`<html><head>
+				<title>Plain title | Blog</title>
+				<meta property="og:title" content="Open Graph title">
+				<meta name="description" content="Plain
+					description">
+			</head><body></body></html>`))
+		default:
+			_, _ = w.Write([]byte(`<html><head><title> Only a title </title></head></html>`))
+		}
+	}))
+	defer server.Close()
+
+	metadata, err := FetchPageMetadata(context.Background(), nil, server.URL+"/og")
+	require.NoError(t, err)
+	assert.Equal(t, PageMetadata{Title: "Open Graph title", Description: "Plain description"}, metadata)
+
+	metadata, err = FetchPageMetadata(context.Background(), nil, server.URL+"/plain")
+	require.NoError(t, err)
+	assert.Equal(t, PageMetadata{Title: "Only a title"}, metadata)
+}
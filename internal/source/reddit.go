@@ -0,0 +1,261 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+const (
+	defaultRedditBaseURL = "https://www.reddit.com"
+	defaultRedditLimit   = 25
+	maxRedditLimit       = 100
+)
+
+var subredditName = regexp.MustCompile(`^[A-Za-z0-9_]{2,21}$`)
+
+// RedditConfig selects a subreddit listing and the filters a post must pass
+// to become an item.
+type RedditConfig struct {
+	Subreddit string `json:"subreddit"`
+	// Sort is one of "hot", "new" or "top". Defaults to "hot".
+	Sort string `json:"sort"`
+	// Time is the window of the "top" listing: hour, day, week, month, year
+	// or all. Defaults to "day".
+	Time           string   `json:"time"`
+	Limit          int      `json:"limit"`
+	MinUpvotes     int      `json:"min_upvotes"`
+	MinUpvoteRatio float64  `json:"min_upvote_ratio"`
+	IncludeFlairs  []string `json:"include_flairs"`
+	ExcludeFlairs  []string `json:"exclude_flairs"`
+	AllowNSFW      bool     `json:"allow_nsfw"`
+	AllowStickied  bool     `json:"allow_stickied"`
+	BaseURL        string   `json:"base_url"`
+}
+
+func (c RedditConfig) validate() error {
+	if !subredditName.MatchString(strings.TrimPrefix(c.Subreddit, "r/")) {
+		return fmt.Errorf("reddit source config: invalid subreddit %q", c.Subreddit)
+	}
+
+	switch c.Sort {
+	case "", "hot", "new", "top":
+	default:
+		return fmt.Errorf("reddit source config: unknown sort %q, expected hot, new or top", c.Sort)
+	}
+
+	switch c.Time {
+	case "", "hour", "day", "week", "month", "year", "all":
+	default:
+		return fmt.Errorf("reddit source config: unknown time window %q", c.Time)
+	}
+
+	if c.Limit < 0 || c.Limit > maxRedditLimit {
+		return fmt.Errorf("reddit source config: limit must be between 1 and %d", maxRedditLimit)
+	}
+
+	if c.MinUpvoteRatio < 0 || c.MinUpvoteRatio > 1 {
+		return fmt.Errorf("reddit source config: min_upvote_ratio must be between 0 and 1")
+	}
+
+	return nil
+}
+
+// RedditSource reads posts from a subreddit's JSON listing.
+type RedditSource struct {
+	SourceId   int64
+	SourceName string
+	Config     RedditConfig
+	client     *http.Client
+}
+
+func newRedditSource(m model.Source, limiter *ratelimit.Limiter) (Source, error) {
+	config, err := decodeConfig[RedditConfig](m.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	config.Subreddit = strings.TrimPrefix(config.Subreddit, "r/")
+	if config.Sort == "" {
+		config.Sort = "hot"
+	}
+	if config.Time == "" {
+		config.Time = "day"
+	}
+	if config.Limit == 0 {
+		config.Limit = defaultRedditLimit
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = defaultRedditBaseURL
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+
+	return &RedditSource{
+		SourceId:   m.ID,
+		SourceName: m.Name,
+		Config:     config,
+		client:     newHTTPClient(m, limiter, config.BaseURL),
+	}, nil
+}
+
+func validateRedditConfig(config json.RawMessage) error {
+	redditConfig, err := decodeConfig[RedditConfig](config)
+	if err != nil {
+		return err
+	}
+
+	return redditConfig.validate()
+}
+
+type redditListing struct {
+	Data struct {
+		Children []struct {
+			Kind string     `json:"kind"`
+			Data redditPost `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+type redditPost struct {
+	Title         string  `json:"title"`
+	URL           string  `json:"url"`
+	Permalink     string  `json:"permalink"`
+	Selftext      string  `json:"selftext"`
+	IsSelf        bool    `json:"is_self"`
+	Ups           int     `json:"ups"`
+	UpvoteRatio   float64 `json:"upvote_ratio"`
+	LinkFlairText string  `json:"link_flair_text"`
+	Over18        bool    `json:"over_18"`
+	Stickied      bool    `json:"stickied"`
+	CreatedUTC    float64 `json:"created_utc"`
+	Author        string  `json:"author"`
+}
+
+func (s *RedditSource) Fetch(ctx context.Context) ([]model.Item, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(s.Config.Limit))
+	query.Set("raw_json", "1")
+	if s.Config.Sort == "top" {
+		query.Set("t", s.Config.Time)
+	}
+
+	listingURL := fmt.Sprintf("%s/r/%s/%s.json?%s", s.Config.BaseURL, s.Config.Subreddit, s.Config.Sort, query.Encode())
+
+	body, _, err := fetchBody(ctx, s.client, listingURL, "application/json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load r/%s: %w", s.Config.Subreddit, err)
+	}
+
+	var listing redditListing
+	if err := json.Unmarshal(body, &listing); err != nil {
+		return nil, fmt.Errorf("failed to parse r/%s listing: %w", s.Config.Subreddit, err)
+	}
+
+	var items []model.Item
+	for _, child := range listing.Data.Children {
+		if child.Kind != "t3" || !s.qualifies(child.Data) {
+			continue
+		}
+
+		items = append(items, s.postToItem(child.Data))
+	}
+
+	return items, nil
+}
+
+func (s *RedditSource) qualifies(post redditPost) bool {
+	if post.Title == "" {
+		return false
+	}
+
+	if (post.Over18 && !s.Config.AllowNSFW) || (post.Stickied && !s.Config.AllowStickied) {
+		return false
+	}
+
+	if post.Ups < s.Config.MinUpvotes || post.UpvoteRatio < s.Config.MinUpvoteRatio {
+		return false
+	}
+
+	if containsFold(s.Config.ExcludeFlairs, post.LinkFlairText) {
+		return false
+	}
+
+	if len(s.Config.IncludeFlairs) > 0 && !containsFold(s.Config.IncludeFlairs, post.LinkFlairText) {
+		return false
+	}
+
+	return true
+}
+
+func (s *RedditSource) postToItem(post redditPost) model.Item {
+	permalink := defaultRedditBaseURL + post.Permalink
+
+	link := post.URL
+	if post.IsSelf || link == "" {
+		link = permalink
+	}
+
+	item := model.Item{
+		Title:        post.Title,
+		Link:         link,
+		CommentsLink: permalink,
+		Date:         redditTime(post.CreatedUTC),
+		SourceName:   s.SourceName,
+	}
+
+	if post.IsSelf {
+		// Self posts have no page of their own to summarize.
+		item.Summary = post.Selftext
+	}
+
+	if post.LinkFlairText != "" {
+		item.Categories = []string{post.LinkFlairText}
+	}
+
+	if post.Author != "" {
+		item.Authors = []string{post.Author}
+	}
+
+	return item
+}
+
+func redditTime(createdUTC float64) time.Time {
+	seconds, fraction := math.Modf(createdUTC)
+	return time.Unix(int64(seconds), int64(fraction*float64(time.Second))).UTC()
+}
+
+func containsFold(values []string, value string) bool {
+	if value == "" {
+		return false
+	}
+
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *RedditSource) ID() int64 {
+	return s.SourceId
+}
+
+func (s *RedditSource) Name() string {
+	return s.SourceName
+}
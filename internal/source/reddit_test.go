@@ -0,0 +1,61 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+func TestRedditSource_Fetch(t *testing.T) {
+	listing, err := os.ReadFile("testdata/reddit.json")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/r/MachineLearning/top.json", r.URL.Path)
+		assert.Equal(t, "week", r.URL.Query().Get("t"))
+		_, _ = w.Write(listing)
+	}))
+	defer server.Close()
+
+	config, err := json.Marshal(RedditConfig{
+		Subreddit:      "r/MachineLearning",
+		Sort:           "top",
+		Time:           "week",
+		MinUpvotes:     100,
+		MinUpvoteRatio: 0.9,
+		ExcludeFlairs:  []string{"jobs"},
+		BaseURL:        server.URL,
+	})
+	require.NoError(t, err)
+
+	src, err := newRedditSource(model.Source{ID: 1, Name: "r/ML", Kind: model.SourceKindReddit, Config: config}, nil)
+	require.NoError(t, err)
+
+	items, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, "[R] Mixture of depths, revisited", items[0].Title)
+	assert.Equal(t, "https://arxiv.org/abs/2506.00001", items[0].Link)
+	assert.Equal(t, "https://www.reddit.com/r/MachineLearning/comments/b2/mod/", items[0].CommentsLink)
+	assert.Empty(t, items[0].Summary, "link posts are summarized from the linked page")
+	assert.Equal(t, []string{"Research"}, items[0].Categories)
+
+	assert.Equal(t, "[D] How do you track experiments?", items[1].Title)
+	assert.Equal(t, "We use spreadsheets and it hurts.", items[1].Summary)
+}
+
+func TestValidateRedditConfig(t *testing.T) {
+	assert.NoError(t, validateRedditConfig(json.RawMessage(`{"subreddit":"LocalLLaMA","sort":"new"}`)))
+	assert.Error(t, validateRedditConfig(json.RawMessage(`{}`)), "subreddit is required")
+	assert.Error(t, validateRedditConfig(json.RawMessage(`{"subreddit":"ml","sort":"best"}`)))
+	assert.Error(t, validateRedditConfig(json.RawMessage(`{"subreddit":"ml","min_upvote_ratio":1.5}`)))
+}
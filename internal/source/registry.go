@@ -0,0 +1,123 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+// Source is a single place items are fetched from.
+type Source interface {
+	ID() int64
+	Name() string
+	Fetch(ctx context.Context) ([]model.Item, error)
+}
+
+// Constructor builds a source of one kind from its model. The source's
+// requests go through the limiter.
+type Constructor func(m model.Source, limiter *ratelimit.Limiter) (Source, error)
+
+// ConfigValidator checks the kind-specific config before a source is saved.
+type ConfigValidator func(config json.RawMessage) error
+
+type kind struct {
+	construct Constructor
+	validate  ConfigValidator
+}
+
+// Registry maps source kinds to their constructors.
+type Registry struct {
+	kinds   map[string]kind
+	limiter *ratelimit.Limiter
+}
+
+// NewRegistry returns an empty registry whose sources send their requests
+// through the limiter.
+func NewRegistry(limiter *ratelimit.Limiter) *Registry {
+	return &Registry{kinds: make(map[string]kind), limiter: limiter}
+}
+
+// NewDefaultRegistry returns a registry with all built-in source kinds.
+func NewDefaultRegistry(limiter *ratelimit.Limiter) *Registry {
+	r := NewRegistry(limiter)
+
+	r.Register(model.SourceKindRSS, newRSSSource, validateRSSConfig)
+	r.Register(model.SourceKindJSONFeed, newJSONFeedSource, validateJSONFeedConfig)
+	r.Register(model.SourceKindHTML, newHTMLSource, validateHTMLConfig)
+	r.Register(model.SourceKindHN, newHackerNewsSource, validateHackerNewsConfig)
+	r.Register(model.SourceKindReddit, newRedditSource, validateRedditConfig)
+	r.Register(model.SourceKindArxiv, newArxivSource, validateArxivConfig)
+	r.Register(model.SourceKindTelegram, newTelegramChannelSource, validateTelegramChannelConfig)
+	r.Register(model.SourceKindChannel, newChannelSource, validateChannelConfig)
+
+	return r
+}
+
+func (r *Registry) Register(name string, construct Constructor, validate ConfigValidator) {
+	r.kinds[name] = kind{construct: construct, validate: validate}
+}
+
+// New builds the source for the model. Sources without a kind are RSS.
+func (r *Registry) New(m model.Source) (Source, error) {
+	name := m.Kind
+	if name == "" {
+		name = model.SourceKindRSS
+	}
+
+	k, ok := r.kinds[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown source kind %q", name)
+	}
+
+	return k.construct(m, r.limiter)
+}
+
+// Validate checks the config of a source of the given kind.
+func (r *Registry) Validate(name string, config json.RawMessage) error {
+	k, ok := r.kinds[name]
+	if !ok {
+		return fmt.Errorf("unknown source kind %q", name)
+	}
+
+	if k.validate == nil {
+		return nil
+	}
+
+	return k.validate(config)
+}
+
+// Kinds returns the names of all registered kinds.
+func (r *Registry) Kinds() []string {
+	names := make([]string, 0, len(r.kinds))
+	for name := range r.kinds {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// decodeConfig decodes a kind-specific config, rejecting unknown fields so
+// typos in /addsource are reported instead of silently ignored.
+func decodeConfig[T any](config json.RawMessage) (T, error) {
+	var result T
+
+	if len(bytes.TrimSpace(config)) == 0 || bytes.Equal(bytes.TrimSpace(config), []byte("null")) {
+		return result, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(config))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&result); err != nil {
+		return result, fmt.Errorf("invalid source config: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,32 @@
+package source
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+func TestRegistry_New(t *testing.T) {
+	registry := NewDefaultRegistry(nil)
+
+	src, err := registry.New(model.Source{ID: 7, Name: "Legacy", FeedURL: "https://example.com/feed"})
+	require.NoError(t, err)
+	assert.IsType(t, &RSSSource{}, src, "sources without a kind should be RSS")
+	assert.Equal(t, int64(7), src.ID())
+
+	_, err = registry.New(model.Source{Kind: "carrier-pigeon"})
+	assert.Error(t, err)
+}
+
+func TestRegistry_Validate(t *testing.T) {
+	registry := NewDefaultRegistry(nil)
+
+	assert.NoError(t, registry.Validate(model.SourceKindRSS, nil))
+	assert.NoError(t, registry.Validate(model.SourceKindRSS, json.RawMessage(`{}`)))
+	assert.Error(t, registry.Validate(model.SourceKindRSS, json.RawMessage(`{"unknown":1}`)))
+	assert.Error(t, registry.Validate("carrier-pigeon", nil))
+}
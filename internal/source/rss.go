@@ -1,43 +1,210 @@
 package source
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"neuro_scout_bot_v1/internal/httpclient"
 	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
 	"strings"
 	"time"
 
 	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+	"github.com/mmcdole/gofeed/rss"
 )
 
 type RSSSource struct {
-	URL        string
-	SourceId   int64
-	SourceName string
-	Priority   int64
-	client     *http.Client
+	URL          string
+	SourceId     int64
+	SourceName   string
+	Priority     int64
+	ETag         string
+	LastModified string
+	client       *http.Client
+	limiter      *ratelimit.Limiter
+	hints        FeedHints
 }
 
-func NewRSSSourceFromModel(m model.Source) *RSSSource {
+func NewRSSSourceFromModel(m model.Source, limiter *ratelimit.Limiter) *RSSSource {
 	return &RSSSource{
-		URL:        m.FeedURL,
-		SourceId:   m.ID,
-		SourceName: m.Name,
-		Priority:   m.Priority,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		URL:          m.FeedURL,
+		SourceId:     m.ID,
+		SourceName:   m.Name,
+		Priority:     m.Priority,
+		ETag:         m.ETag,
+		LastModified: m.LastModified,
+		client:       newHTTPClient(m, limiter),
+		limiter:      limiter,
 	}
 }
 
+func newRSSSource(m model.Source, limiter *ratelimit.Limiter) (Source, error) {
+	if _, err := decodeConfig[struct{}](m.Config); err != nil {
+		return nil, err
+	}
+
+	return NewRSSSourceFromModel(m, limiter), nil
+}
+
+func validateRSSConfig(config json.RawMessage) error {
+	_, err := decodeConfig[struct{}](config)
+	return err
+}
+
+// errNotModified is returned by loadFeed when the server answers 304 to a
+// conditional request.
+var errNotModified = errors.New("feed not modified")
+
 func (s *RSSSource) Fetch(ctx context.Context) ([]model.Item, error) {
-	feed, err := s.loadFeedWithRetry(ctx, s.URL)
+	items, err := s.loadFeedWithRetry(ctx, s.URL)
+	if errors.Is(err, errNotModified) {
+		log.Printf("[INFO] Feed %s not modified since last fetch", s.URL)
+		return nil, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to load feed from %s: %w", s.URL, err)
 	}
 
+	return items, nil
+}
+
+const (
+	// feedAttempts is how many times a feed is requested before giving up.
+	feedAttempts = 5
+	// retryBackoff is the wait before the first retry; it doubles after
+	// every failed attempt.
+	retryBackoff = 3 * time.Second
+	// maxRetryWait is the longest a throttled feed is waited for before the
+	// fetch gives up and leaves it to the next scheduled one.
+	maxRetryWait = time.Minute
+)
+
+// loadFeedWithRetry retries network errors, 5xx responses and short
+// throttling.
+func (s *RSSSource) loadFeedWithRetry(ctx context.Context, url string) ([]model.Item, error) {
+	var lastErr error
+	for attempt := 0; attempt < feedAttempts; attempt++ {
+		if attempt > 0 {
+			wait := retryBackoff << (attempt - 1)
+			if until, throttled := ratelimit.ThrottledUntil(lastErr); throttled {
+				wait = time.Until(until)
+				if wait > maxRetryWait {
+					return nil, lastErr
+				}
+			}
+
+			log.Printf("[INFO] Retry %d for %s, waiting %v", attempt, url, wait.Round(time.Second))
+			if err := ratelimit.Sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+
+			// The wait happened here, outside the request timeout.
+			var err error
+			if ctx, err = s.limiter.Acquire(ctx, ratelimit.HostOf(url)); err != nil {
+				return nil, err
+			}
+		}
+
+		items, err := s.loadFeed(ctx, url)
+		if err == nil || errors.Is(err, errNotModified) {
+			return items, err
+		}
+
+		lastErr = err
+
+		var status *ratelimit.StatusError
+		if errors.As(err, &status) && !status.Temporary() {
+			return nil, err
+		}
+		if status != nil && status.Throttled() {
+			log.Printf("[WARN] Rate limit detected for %s: %v", url, err)
+		}
+	}
+	return nil, lastErr
+}
+
+func (s *RSSSource) loadFeed(ctx context.Context, url string) ([]model.Item, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", httpclient.UserAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/xml, application/atom+xml, application/feed+json, text/xml, */*")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	req.Header.Set("Connection", "keep-alive")
+
+	if s.ETag != "" {
+		req.Header.Set("If-None-Match", s.ETag)
+	}
+	if s.LastModified != "" {
+		req.Header.Set("If-Modified-Since", s.LastModified)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, errNotModified
+	}
+
+	if err := ratelimit.CheckResponse(resp); err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+
+	items, hints, err := ParseFeed(body, resp.Header.Get("Content-Type"), s.SourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	hints.Hub, hints.Self = hubLinks(resp.Header, body)
+	s.hints = hints
+
+	s.ETag = resp.Header.Get("ETag")
+	s.LastModified = resp.Header.Get("Last-Modified")
+
+	return items, nil
+}
+
+// ParseFeed reads the items of an RSS, Atom or JSON feed, along with the
+// polling hints of RSS and Atom feeds.
+func ParseFeed(body []byte, contentType, sourceName string) ([]model.Item, FeedHints, error) {
+	if isJSONFeedContentType(contentType) {
+		items, err := parseJSONFeed(body, sourceName)
+		return items, FeedHints{}, err
+	}
+
+	feed, err := gofeed.NewParser().Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, FeedHints{}, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	var rssFeed *rss.Feed
+	if feed.FeedType == "rss" {
+		// The universal feed drops <ttl> and <skipHours>, so read them from
+		// the RSS-specific representation.
+		rssFeed, _ = (&rss.Parser{}).Parse(bytes.NewReader(body))
+	}
+
+	return itemsFromFeed(feed, sourceName), feedHints(feed, rssFeed), nil
+}
+
+func itemsFromFeed(feed *gofeed.Feed, sourceName string) []model.Item {
 	items := make([]model.Item, 0, len(feed.Items))
 	for _, item := range feed.Items {
 		var pubDate time.Time
@@ -48,78 +215,117 @@ func (s *RSSSource) Fetch(ctx context.Context) ([]model.Item, error) {
 		items = append(items, model.Item{
 			Title:      item.Title,
 			Categories: item.Categories,
+			Authors:    feedItemAuthors(item),
 			Link:       item.Link,
+			GUID:       item.GUID,
+			ImageURL:   feedItemImage(item),
 			Date:       pubDate,
+			Updated:    feedItemUpdated(item),
 			Summary:    item.Description,
-			SourceName: s.SourceName,
+			Content:    item.Content,
+			SourceName: sourceName,
 		})
 	}
-	return items, nil
+
+	return items
 }
 
-func (s *RSSSource) loadFeedWithRetry(ctx context.Context, url string) (*gofeed.Feed, error) {
-	var lastErr error
-	for attempt := 0; attempt < 5; attempt++ {
-		if attempt > 0 {
-			backoff := time.Duration(1<<uint(attempt)) * 3 * time.Second
-			log.Printf("[INFO] Retry %d for %s, waiting %v", attempt, url, backoff)
-			time.Sleep(backoff)
+// feedItemUpdated returns the update date of Atom entries, or of RSS items
+// that borrow <atom:updated> or <dc:modified>.
+func feedItemUpdated(item *gofeed.Item) time.Time {
+	if item.UpdatedParsed != nil {
+		return *item.UpdatedParsed
+	}
+
+	for _, value := range []string{extensionValue(item, "atom", "updated"), extensionValue(item, "dc", "modified")} {
+		if date, err := time.Parse(time.RFC3339, strings.TrimSpace(value)); err == nil {
+			return date
 		}
+	}
 
-		feed, err := s.loadFeed(ctx, url)
-		if err == nil {
-			return feed, nil
+	return time.Time{}
+}
+
+func extensionValue(item *gofeed.Item, namespace, name string) string {
+	for _, e := range item.Extensions[namespace][name] {
+		if e.Value != "" {
+			return e.Value
 		}
+	}
 
-		if strings.Contains(err.Error(), "429") ||
-			strings.Contains(err.Error(), "too many requests") ||
-			strings.Contains(err.Error(), "rate limit") {
-			log.Printf("[WARN] Rate limit detected for %s: %v", url, err)
-			time.Sleep(time.Duration(15*(1<<uint(attempt))) * time.Second)
+	return ""
+}
+
+func feedItemAuthors(item *gofeed.Item) []string {
+	var authors []string
+	for _, author := range item.Authors {
+		if author != nil && author.Name != "" {
+			authors = append(authors, author.Name)
 		}
+	}
 
-		lastErr = err
+	if len(authors) == 0 && item.Author != nil && item.Author.Name != "" {
+		authors = append(authors, item.Author.Name)
 	}
-	return nil, lastErr
+
+	return authors
 }
 
-func (s *RSSSource) loadFeed(ctx context.Context, url string) (*gofeed.Feed, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// feedItemImage returns the item's image, an image enclosure, or a Media RSS
+// thumbnail or image, in that order.
+func feedItemImage(item *gofeed.Item) string {
+	if item.Image != nil && item.Image.URL != "" {
+		return item.Image.URL
 	}
 
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.114 Safari/537.36")
-	req.Header.Set("Accept", "application/rss+xml, application/xml, application/atom+xml, text/xml, */*")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
-	req.Header.Set("Cache-Control", "no-cache")
-	req.Header.Set("Connection", "keep-alive")
+	for _, enclosure := range item.Enclosures {
+		if enclosure != nil && strings.HasPrefix(enclosure.Type, "image/") && enclosure.URL != "" {
+			return enclosure.URL
+		}
+	}
 
-	var (
-		feedCH = make(chan *gofeed.Feed)
-		errCH  = make(chan error)
-	)
+	media := item.Extensions["media"]
+	for _, thumbnail := range media["thumbnail"] {
+		if url := thumbnail.Attrs["url"]; url != "" {
+			return url
+		}
+	}
 
-	go func() {
-		parser := gofeed.NewParser()
-		parser.Client = s.client
+	if url := mediaImage(media["content"]); url != "" {
+		return url
+	}
 
-		feed, err := parser.ParseURLWithContext(url, ctx)
-		if err != nil {
-			errCH <- err
-			return
+	// media:content is often nested in a media:group.
+	for _, group := range media["group"] {
+		if url := mediaImage(group.Children["content"]); url != "" {
+			return url
 		}
-		feedCH <- feed
-	}()
+	}
 
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case err := <-errCH:
-		return nil, err
-	case feed := <-feedCH:
-		return feed, nil
+	return ""
+}
+
+func mediaImage(contents []ext.Extension) string {
+	for _, content := range contents {
+		medium, contentType := content.Attrs["medium"], content.Attrs["type"]
+		if url := content.Attrs["url"]; url != "" && (medium == "image" || strings.HasPrefix(contentType, "image/")) {
+			return url
+		}
 	}
+
+	return ""
+}
+
+// FetchHints returns the polling hints published by the feed on the last
+// successful fetch.
+func (s *RSSSource) FetchHints() FeedHints {
+	return s.hints
+}
+
+// CacheValidators returns the ETag and Last-Modified values of the last
+// successful response, to be sent with the next conditional request.
+func (s *RSSSource) CacheValidators() (etag string, lastModified string) {
+	return s.ETag, s.LastModified
 }
 
 func (s *RSSSource) ID() int64 {
@@ -0,0 +1,159 @@
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+const testRSSFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Test feed</title>
+    <item>
+      <title>First post</title>
+      <link>https://example.com/first</link>
+      <pubDate>Mon, 02 Jun 2025 10:00:00 GMT</pubDate>
+    </item>
+  </channel>
+</rss>`
+
+func TestRSSSource_FetchConditional(t *testing.T) {
+	const etag = `"v1"`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Mon, 02 Jun 2025 10:00:00 GMT")
+		_, _ = w.Write([]byte(testRSSFeed))
+	}))
+	defer server.Close()
+
+	source := NewRSSSourceFromModel(model.Source{ID: 1, Name: "Test", FeedURL: server.URL}, nil)
+
+	items, err := source.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "First post", items[0].Title)
+
+	gotETag, gotLastModified := source.CacheValidators()
+	assert.Equal(t, etag, gotETag)
+	assert.Equal(t, "Mon, 02 Jun 2025 10:00:00 GMT", gotLastModified)
+
+	items, err = source.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	gotETag, _ = source.CacheValidators()
+	assert.Equal(t, etag, gotETag, "validators should survive a 304 response")
+}
+
+func TestRSSSource_FetchThrottled(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+
+		switch r.URL.Path {
+		case "/gone":
+			http.NotFound(w, r)
+		case "/slow-down":
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			if n == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_, _ = w.Write([]byte(testRSSFeed))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	start := time.Now()
+	items, err := NewRSSSourceFromModel(model.Source{FeedURL: server.URL + "/feed"}, nil).Fetch(ctx)
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second, "Retry-After should be honored")
+
+	requests.Store(0)
+	_, err = NewRSSSourceFromModel(model.Source{FeedURL: server.URL + "/gone"}, nil).Fetch(ctx)
+	require.Error(t, err)
+	assert.EqualValues(t, 1, requests.Load(), "a missing feed is not retried")
+
+	requests.Store(0)
+	_, err = NewRSSSourceFromModel(model.Source{FeedURL: server.URL + "/slow-down"}, nil).Fetch(ctx)
+	until, throttled := ratelimit.ThrottledUntil(err)
+	require.True(t, throttled)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), until, time.Minute)
+	assert.EqualValues(t, 1, requests.Load(), "a long Retry-After is left to the next fetch")
+}
+
+func TestRSSSource_itemsFromFeed(t *testing.T) {
+	const feed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/"
+     xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:media="http://search.yahoo.com/mrss/"
+     xmlns:atom="http://www.w3.org/2005/Atom">
+  <channel>
+    <title>Test feed</title>
+    <item>
+      <title>With media</title>
+      <link>https://example.com/media</link>
+      <guid isPermaLink="false">post-42</guid>
+      <dc:creator>Ada Lovelace</dc:creator>
+      <category>AI</category>
+      <category>Research</category>
+      <description>Short description.</description>
+      <content:encoded><![CDATA[<p>Full <b>body</b>.</p>]]></content:encoded>
+      <media:group>
+        <media:content url="https://example.com/video.mp4" type="video/mp4"/>
+        <media:content url="https://example.com/cover.jpg" medium="image"/>
+      </media:group>
+      <pubDate>Mon, 02 Jun 2025 10:00:00 GMT</pubDate>
+      <atom:updated>2025-06-03T08:00:00Z</atom:updated>
+    </item>
+    <item>
+      <title>With enclosure</title>
+      <link>https://example.com/enclosure</link>
+      <enclosure url="https://example.com/episode.mp3" type="audio/mpeg" length="1"/>
+      <enclosure url="https://example.com/thumb.png" type="image/png" length="1"/>
+    </item>
+  </channel>
+</rss>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(feed))
+	}))
+	defer server.Close()
+
+	items, err := NewRSSSourceFromModel(model.Source{ID: 1, Name: "Test", FeedURL: server.URL}, nil).Fetch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	first := items[0]
+	assert.Equal(t, "post-42", first.GUID)
+	assert.Equal(t, []string{"Ada Lovelace"}, first.Authors)
+	assert.Equal(t, []string{"AI", "Research"}, first.Categories)
+	assert.Equal(t, "Short description.", first.Summary)
+	assert.Equal(t, "<p>Full <b>body</b>.</p>", first.Content)
+	assert.Equal(t, "https://example.com/cover.jpg", first.ImageURL)
+	assert.Equal(t, time.Date(2025, 6, 3, 8, 0, 0, 0, time.UTC), first.Updated.UTC())
+
+	assert.Equal(t, "https://example.com/thumb.png", items[1].ImageURL)
+	assert.Empty(t, items[1].GUID)
+}
@@ -0,0 +1,112 @@
+package source
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/rss"
+)
+
+// FeedHints are the polling hints a feed publishes about itself.
+type FeedHints struct {
+	// TTL comes from the RSS <ttl> element.
+	TTL time.Duration
+	// UpdatePeriod comes from sy:updatePeriod divided by sy:updateFrequency.
+	UpdatePeriod time.Duration
+	// SkipHours are the UTC hours listed in the RSS <skipHours> element.
+	SkipHours []int
+	// Hub is the WebSub hub the feed advertises, and Self the topic URL to
+	// subscribe to.
+	Hub  string
+	Self string
+}
+
+// Interval returns the polling interval suggested by the feed, or zero if
+// the feed does not publish one.
+func (h FeedHints) Interval() time.Duration {
+	if h.TTL > 0 {
+		return h.TTL
+	}
+
+	return h.UpdatePeriod
+}
+
+// NextAllowed moves t forward to the start of the first hour that is not
+// listed in SkipHours.
+func (h FeedHints) NextAllowed(t time.Time) time.Time {
+	if len(h.SkipHours) == 0 {
+		return t
+	}
+
+	skip := make(map[int]bool, len(h.SkipHours))
+	for _, hour := range h.SkipHours {
+		skip[hour] = true
+	}
+
+	utc := t.UTC()
+	for i := 0; i < 24 && skip[utc.Hour()]; i++ {
+		utc = utc.Truncate(time.Hour).Add(time.Hour)
+	}
+
+	return utc
+}
+
+func feedHints(feed *gofeed.Feed, rssFeed *rss.Feed) FeedHints {
+	var hints FeedHints
+
+	if rssFeed != nil {
+		if ttl, err := strconv.Atoi(strings.TrimSpace(rssFeed.TTL)); err == nil && ttl > 0 {
+			hints.TTL = time.Duration(ttl) * time.Minute
+		}
+
+		for _, hour := range rssFeed.SkipHours {
+			if h, err := strconv.Atoi(strings.TrimSpace(hour)); err == nil && h >= 0 && h < 24 {
+				hints.SkipHours = append(hints.SkipHours, h)
+			}
+		}
+	}
+
+	if feed != nil {
+		hints.UpdatePeriod = syndicationPeriod(feed)
+	}
+
+	return hints
+}
+
+// syndicationPeriod reads the RSS syndication module (sy:) elements.
+func syndicationPeriod(feed *gofeed.Feed) time.Duration {
+	sy, ok := feed.Extensions["sy"]
+	if !ok {
+		return 0
+	}
+
+	var period time.Duration
+	if values := sy["updatePeriod"]; len(values) > 0 {
+		switch strings.ToLower(strings.TrimSpace(values[0].Value)) {
+		case "hourly":
+			period = time.Hour
+		case "daily":
+			period = 24 * time.Hour
+		case "weekly":
+			period = 7 * 24 * time.Hour
+		case "monthly":
+			period = 30 * 24 * time.Hour
+		case "yearly":
+			period = 365 * 24 * time.Hour
+		}
+	}
+
+	if period == 0 {
+		return 0
+	}
+
+	if values := sy["updateFrequency"]; len(values) > 0 {
+		if frequency, err := strconv.Atoi(strings.TrimSpace(values[0].Value)); err == nil && frequency > 0 {
+			period /= time.Duration(frequency)
+		}
+	}
+
+	return period
+}
@@ -0,0 +1,48 @@
+package source
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/rss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHintedFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:sy="http://purl.org/rss/1.0/modules/syndication/">
+  <channel>
+    <title>Hinted feed</title>
+    <ttl>90</ttl>
+    <sy:updatePeriod>daily</sy:updatePeriod>
+    <sy:updateFrequency>4</sy:updateFrequency>
+    <skipHours><hour>0</hour><hour>1</hour><hour>2</hour></skipHours>
+  </channel>
+</rss>`
+
+func TestFeedHints(t *testing.T) {
+	feed, err := gofeed.NewParser().ParseString(testHintedFeed)
+	require.NoError(t, err)
+
+	rssFeed, err := (&rss.Parser{}).Parse(strings.NewReader(testHintedFeed))
+	require.NoError(t, err)
+
+	hints := feedHints(feed, rssFeed)
+
+	assert.Equal(t, 90*time.Minute, hints.TTL)
+	assert.Equal(t, 6*time.Hour, hints.UpdatePeriod)
+	assert.Equal(t, []int{0, 1, 2}, hints.SkipHours)
+	assert.Equal(t, 90*time.Minute, hints.Interval(), "ttl should win over sy:updatePeriod")
+}
+
+func TestFeedHints_NextAllowed(t *testing.T) {
+	hints := FeedHints{SkipHours: []int{0, 1, 2}}
+
+	inSkipped := time.Date(2025, 6, 2, 1, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 6, 2, 3, 0, 0, 0, time.UTC), hints.NextAllowed(inSkipped))
+
+	allowed := time.Date(2025, 6, 2, 5, 30, 0, 0, time.UTC)
+	assert.Equal(t, allowed, hints.NextAllowed(allowed))
+}
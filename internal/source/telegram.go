@@ -0,0 +1,258 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/ratelimit"
+)
+
+const (
+	defaultTelegramBaseURL       = "https://t.me"
+	defaultTelegramBackfillPages = 3
+	maxTelegramBackfillPages     = 10
+)
+
+var telegramChannelName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{3,31}$`)
+
+// TelegramChannelConfig names a public channel read through its t.me/s/
+// web preview.
+type TelegramChannelConfig struct {
+	Channel string `json:"channel"`
+	// BackfillPages is how many preview pages are read on the first fetch,
+	// paging backwards from the newest post. Later fetches read one page.
+	BackfillPages int    `json:"backfill_pages"`
+	BaseURL       string `json:"base_url"`
+}
+
+func (c TelegramChannelConfig) validate() error {
+	if !telegramChannelName.MatchString(strings.TrimPrefix(c.Channel, "@")) {
+		return fmt.Errorf("telegram source config: invalid channel %q", c.Channel)
+	}
+
+	if c.BackfillPages < 0 || c.BackfillPages > maxTelegramBackfillPages {
+		return fmt.Errorf("telegram source config: backfill_pages must be between 1 and %d", maxTelegramBackfillPages)
+	}
+
+	return nil
+}
+
+// TelegramChannelSource scrapes the posts of a public Telegram channel.
+type TelegramChannelSource struct {
+	SourceId   int64
+	SourceName string
+	Config     TelegramChannelConfig
+	// firstFetch is set until the source has been fetched successfully once.
+	firstFetch bool
+	client     *http.Client
+}
+
+func newTelegramChannelSource(m model.Source, limiter *ratelimit.Limiter) (Source, error) {
+	config, err := decodeConfig[TelegramChannelConfig](m.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	config.Channel = strings.TrimPrefix(config.Channel, "@")
+	if config.BackfillPages == 0 {
+		config.BackfillPages = defaultTelegramBackfillPages
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = defaultTelegramBaseURL
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+
+	return &TelegramChannelSource{
+		SourceId:   m.ID,
+		SourceName: m.Name,
+		Config:     config,
+		firstFetch: m.Health.LastSuccessAt.IsZero(),
+		client:     newHTTPClient(m, limiter, config.BaseURL),
+	}, nil
+}
+
+func validateTelegramChannelConfig(config json.RawMessage) error {
+	telegramConfig, err := decodeConfig[TelegramChannelConfig](config)
+	if err != nil {
+		return err
+	}
+
+	return telegramConfig.validate()
+}
+
+func (s *TelegramChannelSource) Fetch(ctx context.Context) ([]model.Item, error) {
+	pages := 1
+	if s.firstFetch {
+		pages = s.Config.BackfillPages
+	}
+
+	var (
+		items  []model.Item
+		before int64
+	)
+
+	for page := 0; page < pages; page++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		pageItems, oldest, err := s.fetchPage(ctx, before)
+		if err != nil {
+			// Older pages are a bonus, the newest one is what matters.
+			if page > 0 {
+				break
+			}
+			return nil, err
+		}
+
+		items = append(items, pageItems...)
+
+		if oldest <= 1 || (before != 0 && oldest >= before) {
+			break
+		}
+		before = oldest
+	}
+
+	s.firstFetch = false
+
+	return items, nil
+}
+
+// fetchPage reads one preview page ending before the given post ID, or the
+// newest page for zero. It returns the ID of the oldest post on the page.
+func (s *TelegramChannelSource) fetchPage(ctx context.Context, before int64) ([]model.Item, int64, error) {
+	pageURL := fmt.Sprintf("%s/s/%s", s.Config.BaseURL, s.Config.Channel)
+	if before > 0 {
+		pageURL += "?before=" + strconv.FormatInt(before, 10)
+	}
+
+	body, _, err := fetchBody(ctx, s.client, pageURL, "text/html")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load channel %s: %w", s.Config.Channel, err)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse channel %s: %w", s.Config.Channel, err)
+	}
+
+	var (
+		items  []model.Item
+		oldest int64
+	)
+
+	doc.Find(".tgme_widget_message[data-post]").Each(func(_ int, sel *goquery.Selection) {
+		post, _ := sel.Attr("data-post")
+
+		id, err := telegramPostID(post)
+		if err != nil {
+			return
+		}
+
+		if oldest == 0 || id < oldest {
+			oldest = id
+		}
+
+		if item, ok := s.parsePost(sel, post); ok {
+			items = append(items, item)
+		}
+	})
+
+	return items, oldest, nil
+}
+
+func (s *TelegramChannelSource) parsePost(sel *goquery.Selection, post string) (model.Item, bool) {
+	textSel := sel.Find(".tgme_widget_message_text").First()
+	textSel.Find("br").ReplaceWithHtml("\n")
+
+	text := strings.TrimSpace(textSel.Text())
+	if text == "" {
+		return model.Item{}, false
+	}
+
+	permalink := "https://t.me/" + post
+	if href, ok := sel.Find("a.tgme_widget_message_date").Attr("href"); ok {
+		permalink = href
+	}
+
+	link := firstOutboundLink(textSel)
+	if link == "" {
+		link, _ = sel.Find("a.tgme_widget_message_link_preview").Attr("href")
+	}
+	if link == "" {
+		link = permalink
+	}
+
+	var date time.Time
+	if value, ok := sel.Find(".tgme_widget_message_date time").Attr("datetime"); ok {
+		date, _ = time.Parse(time.RFC3339, value)
+	}
+
+	firstLine, _, _ := strings.Cut(text, "\n")
+
+	return model.Item{
+		Title:        shortTitle(firstLine),
+		Link:         link,
+		CommentsLink: permalink,
+		Date:         date,
+		Summary:      text,
+		SourceName:   s.SourceName,
+	}, true
+}
+
+// firstOutboundLink returns the first link in the post text that leads
+// outside Telegram. Hashtags and mentions link back to t.me.
+func firstOutboundLink(sel *goquery.Selection) string {
+	var link string
+
+	sel.Find("a[href]").EachWithBreak(func(_ int, a *goquery.Selection) bool {
+		href, _ := a.Attr("href")
+
+		u, err := url.Parse(href)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return true
+		}
+
+		if host := strings.ToLower(u.Hostname()); host == "t.me" || host == "telegram.me" {
+			return true
+		}
+
+		link = href
+		return false
+	})
+
+	return link
+}
+
+// telegramPostID parses the ID out of a "channel/123" data-post value.
+func telegramPostID(post string) (int64, error) {
+	_, id, ok := strings.Cut(post, "/")
+	if !ok {
+		return 0, fmt.Errorf("unexpected post reference %q", post)
+	}
+
+	return strconv.ParseInt(id, 10, 64)
+}
+
+func (s *TelegramChannelSource) ID() int64 {
+	return s.SourceId
+}
+
+func (s *TelegramChannelSource) Name() string {
+	return s.SourceName
+}
@@ -0,0 +1,95 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+func newTelegramTestServer(t *testing.T, requests *[]string) *httptest.Server {
+	t.Helper()
+
+	latest, err := os.ReadFile("testdata/telegram_latest.html")
+	require.NoError(t, err)
+	before, err := os.ReadFile("testdata/telegram_before.html")
+	require.NoError(t, err)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/s/ai_digest", r.URL.Path)
+		*requests = append(*requests, r.URL.Query().Get("before"))
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.URL.Query().Get("before") {
+		case "":
+			_, _ = w.Write(latest)
+		case "101":
+			_, _ = w.Write(before)
+		default:
+			_, _ = w.Write([]byte(`<html><body><section class="tgme_channel_history"></section></body></html>`))
+		}
+	}))
+}
+
+func TestTelegramChannelSource_FirstFetchPagesBackwards(t *testing.T) {
+	var requests []string
+	server := newTelegramTestServer(t, &requests)
+	defer server.Close()
+
+	config, err := json.Marshal(TelegramChannelConfig{Channel: "@ai_digest", BaseURL: server.URL})
+	require.NoError(t, err)
+
+	src, err := newTelegramChannelSource(model.Source{ID: 1, Name: "AI Digest", Kind: model.SourceKindTelegram, Config: config}, nil)
+	require.NoError(t, err)
+
+	items, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"", "101", "99"}, requests)
+	require.Len(t, items, 3, "the photo-only post is skipped")
+
+	release := items[0]
+	assert.Equal(t, "New open-weights model released", release.Title)
+	assert.Equal(t, "https://example.com/blog/model-release", release.Link)
+	assert.Equal(t, "https://t.me/ai_digest/101", release.CommentsLink)
+	assert.Equal(t, time.Date(2025, 6, 5, 9, 30, 0, 0, time.UTC), release.Date.UTC())
+	assert.Contains(t, release.Summary, "The weights are on the hub")
+
+	thread := items[1]
+	assert.Equal(t, "Thoughts on evals, thread by @other_channel", thread.Title)
+	assert.Equal(t, "https://t.me/ai_digest/103", thread.Link, "links back to Telegram are not outbound")
+
+	assert.Equal(t, "https://example.com/benchmarks", items[2].Link, "falls back to the link preview")
+}
+
+func TestTelegramChannelSource_LaterFetchReadsNewestPage(t *testing.T) {
+	var requests []string
+	server := newTelegramTestServer(t, &requests)
+	defer server.Close()
+
+	config, err := json.Marshal(TelegramChannelConfig{Channel: "ai_digest", BaseURL: server.URL})
+	require.NoError(t, err)
+
+	src, err := newTelegramChannelSource(model.Source{
+		ID:     1,
+		Name:   "AI Digest",
+		Kind:   model.SourceKindTelegram,
+		Config: config,
+		Health: model.SourceHealth{LastSuccessAt: time.Now()},
+	}, nil)
+	require.NoError(t, err)
+
+	items, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{""}, requests)
+	assert.Len(t, items, 2)
+}
@@ -3,12 +3,16 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/samber/lo"
 
+	"neuro_scout_bot_v1/internal/canonical"
+	"neuro_scout_bot_v1/internal/fingerprint"
 	"neuro_scout_bot_v1/internal/model"
 )
 
@@ -20,56 +24,235 @@ func NewArticleStorage(db *sqlx.DB) *ArticlePostgresStorage {
 	return &ArticlePostgresStorage{db: db}
 }
 
-func (s *ArticlePostgresStorage) Store(ctx context.Context, article model.Article) error {
+// Store saves a new article, unique by canonical link, and links it to its
+// tags. An existing article can only be marked to be published next.
+func (s *ArticlePostgresStorage) Store(ctx context.Context, article model.Article) (model.StoreResult, error) {
 	conn, err := s.db.Connx(ctx)
 	if err != nil {
-		return err
+		return model.ArticleUnchanged, err
 	}
 	defer conn.Close()
 
-	if _, err := conn.ExecContext(
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.ArticleUnchanged, err
+	}
+	defer tx.Rollback()
+
+	canonicalLink := article.CanonicalLink
+	if canonicalLink == "" {
+		canonicalLink = canonical.Clean(article.Link)
+	}
+
+	fp := article.Fingerprint
+	if fp == 0 {
+		fp = fingerprint.Of(article.Title, lo.Ternary(article.Summary != "", article.Summary, article.Content))
+	}
+
+	var (
+		dbFingerprint sql.NullInt64
+		bands         []int32
+	)
+	if fp != 0 {
+		dbFingerprint = sql.NullInt64{Int64: int64(fp), Valid: true}
+		bands = fingerprint.Bands(fp)
+	}
+
+	var (
+		id       int64
+		inserted bool
+	)
+	err = tx.QueryRowxContext(
 		ctx,
-		`INSERT INTO articles (source_id, title, link, summary, published_at)
-	    				VALUES ($1, $2, $3, $4, $5)
-	    				ON CONFLICT DO NOTHING;`,
+		`INSERT INTO articles (source_id, title, link, canonical_link, comments_link, guid, summary, content, image_url, authors, primary_category, categories, fingerprint, fingerprint_bands, publish_next, extraction_status, published_at, source_updated_at)
+	    				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+	    				ON CONFLICT (canonical_link) DO UPDATE SET publish_next = TRUE WHERE EXCLUDED.publish_next
+	    				RETURNING id, (xmax = 0) AS inserted;`,
 		article.SourceID,
 		article.Title,
 		article.Link,
+		canonicalLink,
+		article.CommentsLink,
+		article.GUID,
 		article.Summary,
+		article.Content,
+		article.ImageURL,
+		pq.Array(article.Authors),
+		article.PrimaryCategory,
+		pq.Array(article.Categories),
+		dbFingerprint,
+		pq.Array(bands),
+		article.PublishNext,
+		lo.Ternary(article.Extraction != "", article.Extraction, model.ExtractionPending),
 		article.PublishedAt,
+		sql.NullTime{Time: article.UpdatedAt, Valid: !article.UpdatedAt.IsZero()},
+	).Scan(&id, &inserted)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The article exists and was left as it is.
+		return model.ArticleUnchanged, nil
+	}
+	if err != nil {
+		return model.ArticleUnchanged, err
+	}
+
+	if tags := NormalizeTags(article.Categories); len(tags) > 0 {
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO tags (name) SELECT UNNEST($1::text[]) ON CONFLICT (name) DO NOTHING;`,
+			pq.Array(tags),
+		); err != nil {
+			return model.ArticleUnchanged, err
+		}
+
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO article_tags (article_id, tag_id)
+				SELECT $1, id FROM tags WHERE name = ANY($2::text[])
+				ON CONFLICT DO NOTHING;`,
+			id,
+			pq.Array(tags),
+		); err != nil {
+			return model.ArticleUnchanged, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.ArticleUnchanged, err
+	}
+
+	return lo.Ternary(inserted, model.ArticleInserted, model.ArticleUpdated), nil
+}
+
+// ArticleExists reports whether an article with the link or the canonical
+// link is stored.
+func (s *ArticlePostgresStorage) ArticleExists(ctx context.Context, link, canonicalLink string) (bool, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	var exists bool
+	if err := conn.GetContext(
+		ctx,
+		&exists,
+		`SELECT EXISTS (SELECT 1 FROM articles WHERE link = $1 OR canonical_link = $2 OR link = $2);`,
+		link,
+		canonicalLink,
 	); err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	return exists, nil
 }
 
-func (s *ArticlePostgresStorage) AllNotPosted(ctx context.Context, since time.Time, limit uint64) ([]model.Article, error) {
+// LinkPosted reports whether another article with the same canonical link
+// was already posted.
+func (s *ArticlePostgresStorage) LinkPosted(ctx context.Context, article model.Article) (bool, error) {
 	conn, err := s.db.Connx(ctx)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 	defer conn.Close()
 
-	var articles []dbArticleWithPriority
+	canonicalLink := article.CanonicalLink
+	if canonicalLink == "" {
+		canonicalLink = canonical.Clean(article.Link)
+	}
 
-	if err := conn.SelectContext(
+	var posted bool
+	if err := conn.GetContext(
 		ctx,
-		&articles,
-		`SELECT 
-				a.id AS a_id, 
+		&posted,
+		`SELECT EXISTS (
+			SELECT 1 FROM articles
+			WHERE id <> $1
+				AND posted_at IS NOT NULL
+				AND (canonical_link = $2 OR link = $2 OR link = $3)
+		);`,
+		article.ID,
+		canonicalLink,
+		article.Link,
+	); err != nil {
+		return false, err
+	}
+
+	return posted, nil
+}
+
+// NormalizeTags turns categories into tags: trimmed, lower case and without
+// duplicates.
+func NormalizeTags(categories []string) []string {
+	var tags []string
+	seen := make(map[string]struct{}, len(categories))
+
+	for _, category := range categories {
+		tag := strings.ToLower(strings.TrimSpace(category))
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
+
+// articleColumns are the article columns read into dbArticleWithPriority.
+const articleColumns = `a.id AS a_id,
 				s.priority AS s_priority,
 				s.id AS s_id,
 				a.title AS a_title,
 				a.link AS a_link,
+				a.canonical_link AS a_canonical_link,
+				a.comments_link AS a_comments_link,
+				a.guid AS a_guid,
 				a.summary AS a_summary,
+				a.content AS a_content,
+				a.image_url AS a_image_url,
+				a.authors AS a_authors,
+				a.primary_category AS a_primary_category,
+				a.categories AS a_categories,
+				a.fingerprint AS a_fingerprint,
+				a.body_text AS a_body_text,
+				a.lead_image_url AS a_lead_image_url,
+				a.extraction_status AS a_extraction_status,
+				a.extraction_attempts AS a_extraction_attempts,
+				COALESCE((SELECT ARRAY_AGG(t.name ORDER BY t.name)
+					FROM article_tags art JOIN tags t ON t.id = art.tag_id
+					WHERE art.article_id = a.id), '{}') AS a_tags,
+				a.publish_next AS a_publish_next,
 				a.published_at AS a_published_at,
+				a.source_updated_at AS a_source_updated_at,
 				a.posted_at AS a_posted_at,
-				a.created_at AS a_created_at
+				a.created_at AS a_created_at`
+
+// extractionSettled holds articles back from posting while their text is
+// being extracted, for at most ten minutes.
+const extractionSettled = `(a.extraction_status <> 'pending' OR a.created_at < NOW() - INTERVAL '10 minutes')`
+
+func (s *ArticlePostgresStorage) AllNotPosted(ctx context.Context, since time.Time, limit uint64) ([]model.Article, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var articles []dbArticleWithPriority
+
+	if err := conn.SelectContext(
+		ctx,
+		&articles,
+		`SELECT `+articleColumns+`
 			FROM articles a JOIN sources s ON s.id = a.source_id
 			WHERE a.posted_at IS NULL 
 				AND a.published_at >= $1::timestamp
-			ORDER BY a.created_at DESC, s_priority DESC LIMIT $2;`,
+				AND `+extractionSettled+`
+			ORDER BY a.publish_next DESC, a.created_at DESC, s_priority DESC LIMIT $2;`,
 		since.UTC().Format(time.RFC3339),
 		limit,
 	); err != nil {
@@ -77,15 +260,7 @@ func (s *ArticlePostgresStorage) AllNotPosted(ctx context.Context, since time.Ti
 	}
 
 	return lo.Map(articles, func(article dbArticleWithPriority, _ int) model.Article {
-		return model.Article{
-			ID:          article.ID,
-			SourceID:    article.SourceID,
-			Title:       article.Title,
-			Link:        article.Link,
-			Summary:     article.Summary.String,
-			PublishedAt: article.PublishedAt,
-			CreatedAt:   article.CreatedAt,
-		}
+		return article.toModel()
 	}), nil
 }
 
@@ -109,8 +284,9 @@ func (s *ArticlePostgresStorage) MarkAsPosted(ctx context.Context, article model
 }
 
 // FindArticlesByTimePeriod повертає всі статті, опубліковані після вказаної дати
-// та відсортовані за часом публікації та пріоритетом джерела
-func (s *ArticlePostgresStorage) FindArticlesByTimePeriod(ctx context.Context, since time.Time, limit uint64) ([]model.Article, error) {
+// та відсортовані за часом публікації та пріоритетом джерела. Непорожній tag
+// залишає лише статті з цим тегом.
+func (s *ArticlePostgresStorage) FindArticlesByTimePeriod(ctx context.Context, since time.Time, limit uint64, tag string) ([]model.Article, error) {
 	conn, err := s.db.Connx(ctx)
 	if err != nil {
 		return nil, err
@@ -122,35 +298,53 @@ func (s *ArticlePostgresStorage) FindArticlesByTimePeriod(ctx context.Context, s
 	if err := conn.SelectContext(
 		ctx,
 		&articles,
-		`SELECT 
-				a.id AS a_id, 
-				s.priority AS s_priority,
-				s.id AS s_id,
-				a.title AS a_title,
-				a.link AS a_link,
-				a.summary AS a_summary,
-				a.published_at AS a_published_at,
-				a.posted_at AS a_posted_at,
-				a.created_at AS a_created_at
+		`SELECT `+articleColumns+`
 			FROM articles a JOIN sources s ON s.id = a.source_id
 			WHERE a.published_at >= $1::timestamp
+				AND ($3 = '' OR EXISTS (
+					SELECT 1 FROM article_tags art JOIN tags t ON t.id = art.tag_id
+					WHERE art.article_id = a.id AND t.name = $3))
 			ORDER BY a.published_at DESC, s_priority DESC LIMIT $2;`,
 		since.UTC().Format(time.RFC3339),
 		limit,
+		strings.ToLower(strings.TrimSpace(tag)),
 	); err != nil {
 		return nil, err
 	}
 
 	return lo.Map(articles, func(article dbArticleWithPriority, _ int) model.Article {
-		return model.Article{
-			ID:          article.ID,
-			SourceID:    article.SourceID,
-			Title:       article.Title,
-			Link:        article.Link,
-			Summary:     article.Summary.String,
-			PublishedAt: article.PublishedAt,
-			CreatedAt:   article.CreatedAt,
-		}
+		return article.toModel()
+	}), nil
+}
+
+// FindSourceArticlesByTimePeriod returns the articles of the source published
+// after since, newest first. A zero sourceID means any source.
+func (s *ArticlePostgresStorage) FindSourceArticlesByTimePeriod(ctx context.Context, since time.Time, limit uint64, sourceID int64) ([]model.Article, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var articles []dbArticleWithPriority
+
+	if err := conn.SelectContext(
+		ctx,
+		&articles,
+		`SELECT `+articleColumns+`
+			FROM articles a JOIN sources s ON s.id = a.source_id
+			WHERE a.published_at >= $1::timestamp
+				AND ($3 = 0 OR a.source_id = $3)
+			ORDER BY a.published_at DESC LIMIT $2;`,
+		since.UTC().Format(time.RFC3339),
+		limit,
+		sourceID,
+	); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(articles, func(article dbArticleWithPriority, _ int) model.Article {
+		return article.toModel()
 	}), nil
 }
 
@@ -167,20 +361,12 @@ func (s *ArticlePostgresStorage) HighPriorityNotPosted(ctx context.Context, prio
 	if err := conn.SelectContext(
 		ctx,
 		&articles,
-		`SELECT 
-				a.id AS a_id, 
-				s.priority AS s_priority,
-				s.id AS s_id,
-				a.title AS a_title,
-				a.link AS a_link,
-				a.summary AS a_summary,
-				a.published_at AS a_published_at,
-				a.posted_at AS a_posted_at,
-				a.created_at AS a_created_at
+		`SELECT `+articleColumns+`
 			FROM articles a JOIN sources s ON s.id = a.source_id
 			WHERE a.posted_at IS NULL 
 				AND a.published_at >= $1::timestamp
 				AND s.priority >= $2
+				AND `+extractionSettled+`
 			ORDER BY s.priority DESC, a.created_at DESC LIMIT $3;`,
 		since.UTC().Format(time.RFC3339),
 		priorityThreshold,
@@ -190,61 +376,183 @@ func (s *ArticlePostgresStorage) HighPriorityNotPosted(ctx context.Context, prio
 	}
 
 	return lo.Map(articles, func(article dbArticleWithPriority, _ int) model.Article {
-		return model.Article{
-			ID:          article.ID,
-			SourceID:    article.SourceID,
-			Title:       article.Title,
-			Link:        article.Link,
-			Summary:     article.Summary.String,
-			PublishedAt: article.PublishedAt,
-			CreatedAt:   article.CreatedAt,
-		}
+		return article.toModel()
 	}), nil
 }
 
-// FindRecentUniqueTitles checks if the specified title is unique in the recent period
-func (s *ArticlePostgresStorage) FindRecentUniqueTitles(ctx context.Context, title string, since time.Time) (bool, error) {
+// PendingExtractions returns the newest articles whose text is due to be
+// extracted.
+func (s *ArticlePostgresStorage) PendingExtractions(ctx context.Context, limit uint64) ([]model.Article, error) {
 	conn, err := s.db.Connx(ctx)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	defer conn.Close()
 
-	// Prepare string for searching similar titles
-	titlePattern := "%" + strings.ReplaceAll(strings.ReplaceAll(title, "%", "\\%"), "_", "\\_") + "%"
+	var articles []dbArticleWithPriority
 
-	var count int
-	err = conn.GetContext(
+	if err := conn.SelectContext(
 		ctx,
-		&count,
-		`SELECT COUNT(*) FROM articles 
-		WHERE (
-			title = $1 OR 
-			title ILIKE $2 OR 
-			similarity(title, $1) > 0.6
-		) AND created_at >= $3::timestamp
-		AND (posted_at IS NOT NULL)`, // Adding check for publication status
-		title,
-		titlePattern,
-		since.UTC().Format(time.RFC3339),
-	)
+		&articles,
+		`SELECT `+articleColumns+`
+			FROM articles a JOIN sources s ON s.id = a.source_id
+			WHERE a.extraction_status = $1
+				AND (a.extract_after IS NULL OR a.extract_after <= NOW())
+			ORDER BY a.created_at DESC LIMIT $2;`,
+		model.ExtractionPending,
+		limit,
+	); err != nil {
+		return nil, err
+	}
 
+	return lo.Map(articles, func(article dbArticleWithPriority, _ int) model.Article {
+		return article.toModel()
+	}), nil
+}
+
+// SaveExtraction stores the text, lead image and canonical link extracted
+// from the article's page.
+func (s *ArticlePostgresStorage) SaveExtraction(ctx context.Context, articleID int64, text, leadImageURL, canonicalLink string) error {
+	conn, err := s.db.Connx(ctx)
 	if err != nil {
-		return false, err
+		return err
 	}
+	defer conn.Close()
 
-	// If similar articles are found, then it's not unique
-	return count == 0, nil
+	if _, err := conn.ExecContext(
+		ctx,
+		`UPDATE articles SET
+			body_text = $1,
+			lead_image_url = $2,
+			image_url = CASE WHEN image_url = '' THEN $2 ELSE image_url END,
+			canonical_link = CASE
+				WHEN $5 <> '' AND NOT EXISTS (SELECT 1 FROM articles other WHERE other.canonical_link = $5 AND other.id <> $4) THEN $5
+				ELSE canonical_link
+			END,
+			extraction_status = $3,
+			extraction_attempts = extraction_attempts + 1,
+			extraction_error = '',
+			extract_after = NULL,
+			extracted_at = NOW()
+		WHERE id = $4;`,
+		text,
+		leadImageURL,
+		model.ExtractionDone,
+		articleID,
+		canonicalLink,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PostponeExtraction puts off extracting the article until retryAt without
+// counting an attempt, for hosts that asked to be left alone.
+func (s *ArticlePostgresStorage) PostponeExtraction(ctx context.Context, articleID int64, retryAt time.Time) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(
+		ctx,
+		`UPDATE articles SET extract_after = $1 WHERE id = $2;`,
+		retryAt.UTC(),
+		articleID,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RecordExtractionFailure stores a failed extraction attempt. The article is
+// tried again after retryAt, or given up on when retryAt is zero.
+func (s *ArticlePostgresStorage) RecordExtractionFailure(ctx context.Context, articleID int64, extractErr string, retryAt time.Time) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	status := model.ExtractionPending
+	if retryAt.IsZero() {
+		status = model.ExtractionFailed
+	}
+
+	if _, err := conn.ExecContext(
+		ctx,
+		`UPDATE articles SET
+			extraction_status = $1,
+			extraction_attempts = extraction_attempts + 1,
+			extraction_error = $2,
+			extract_after = $3
+		WHERE id = $4;`,
+		status,
+		extractErr,
+		sql.NullTime{Time: retryAt.UTC(), Valid: !retryAt.IsZero()},
+		articleID,
+	); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 type dbArticleWithPriority struct {
-	ID             int64          `db:"a_id"`
-	SourcePriority int64          `db:"s_priority"`
-	SourceID       int64          `db:"s_id"`
-	Title          string         `db:"a_title"`
-	Link           string         `db:"a_link"`
-	Summary        sql.NullString `db:"a_summary"`
-	PublishedAt    time.Time      `db:"a_published_at"`
-	PostedAt       sql.NullTime   `db:"a_posted_at"`
-	CreatedAt      time.Time      `db:"a_created_at"`
+	ID              int64          `db:"a_id"`
+	SourcePriority  int64          `db:"s_priority"`
+	SourceID        int64          `db:"s_id"`
+	Title           string         `db:"a_title"`
+	Link            string         `db:"a_link"`
+	CanonicalLink   string         `db:"a_canonical_link"`
+	CommentsLink    string         `db:"a_comments_link"`
+	GUID            string         `db:"a_guid"`
+	Summary         sql.NullString `db:"a_summary"`
+	Content         string         `db:"a_content"`
+	ImageURL        string         `db:"a_image_url"`
+	Authors         pq.StringArray `db:"a_authors"`
+	PrimaryCategory string         `db:"a_primary_category"`
+	Categories      pq.StringArray `db:"a_categories"`
+	Fingerprint     sql.NullInt64  `db:"a_fingerprint"`
+	BodyText        string         `db:"a_body_text"`
+	LeadImageURL    string         `db:"a_lead_image_url"`
+	Extraction      string         `db:"a_extraction_status"`
+	ExtractAttempts int            `db:"a_extraction_attempts"`
+	Tags            pq.StringArray `db:"a_tags"`
+	PublishNext     bool           `db:"a_publish_next"`
+	PublishedAt     time.Time      `db:"a_published_at"`
+	UpdatedAt       sql.NullTime   `db:"a_source_updated_at"`
+	PostedAt        sql.NullTime   `db:"a_posted_at"`
+	CreatedAt       time.Time      `db:"a_created_at"`
+}
+
+func (a dbArticleWithPriority) toModel() model.Article {
+	return model.Article{
+		ID:                 a.ID,
+		SourceID:           a.SourceID,
+		Title:              a.Title,
+		Link:               a.Link,
+		CanonicalLink:      a.CanonicalLink,
+		CommentsLink:       a.CommentsLink,
+		GUID:               a.GUID,
+		Summary:            a.Summary.String,
+		Content:            a.Content,
+		ImageURL:           a.ImageURL,
+		Authors:            a.Authors,
+		PrimaryCategory:    a.PrimaryCategory,
+		Categories:         a.Categories,
+		Tags:               a.Tags,
+		Text:               a.BodyText,
+		LeadImageURL:       a.LeadImageURL,
+		Extraction:         a.Extraction,
+		ExtractionAttempts: a.ExtractAttempts,
+		Fingerprint:        uint64(a.Fingerprint.Int64),
+		PublishNext:        a.PublishNext,
+		PublishedAt:        a.PublishedAt,
+		UpdatedAt:          a.UpdatedAt.Time,
+		CreatedAt:          a.CreatedAt,
+	}
 }
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"neuro_scout_bot_v1/internal/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArticlePostgresStorage_Store(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	storage := NewArticleStorage(sqlx.NewDb(mockDB, "sqlmock"))
+
+	article := model.Article{
+		SourceID:    1,
+		Title:       "Sparse attention",
+		Link:        "https://example.com/sparse",
+		Categories:  []string{"AI", " ai ", "Transformers"},
+		PublishedAt: time.Now().UTC(),
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO articles").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "inserted"}).AddRow(42, true))
+	mock.ExpectExec("INSERT INTO tags").
+		WithArgs(`{"ai","transformers"}`).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO article_tags").
+		WithArgs(int64(42), `{"ai","transformers"}`).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	result, err := storage.Store(context.Background(), article)
+	require.NoError(t, err)
+	assert.Equal(t, model.ArticleInserted, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestArticlePostgresStorage_StoreExisting(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	storage := NewArticleStorage(sqlx.NewDb(mockDB, "sqlmock"))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO articles").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "inserted"}))
+	mock.ExpectRollback()
+
+	result, err := storage.Store(context.Background(), model.Article{Link: "https://example.com/old", Categories: []string{"AI"}})
+	require.NoError(t, err)
+	assert.Equal(t, model.ArticleUnchanged, result, "an already stored URL is reported")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestArticlePostgresStorage_StorePublishNext(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	storage := NewArticleStorage(sqlx.NewDb(mockDB, "sqlmock"))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO articles").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "inserted"}).AddRow(42, false))
+	mock.ExpectCommit()
+
+	result, err := storage.Store(context.Background(), model.Article{Link: "https://example.com/old", PublishNext: true})
+	require.NoError(t, err)
+	assert.Equal(t, model.ArticleUpdated, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestArticlePostgresStorage_FindSourceArticlesByTimePeriod(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	storage := NewArticleStorage(sqlx.NewDb(mockDB, "sqlmock"))
+
+	since := time.Date(2025, 6, 14, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("a.source_id = \\$3").
+		WithArgs(since.Format(time.RFC3339), uint64(500), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"a_id"}))
+
+	articles, err := storage.FindSourceArticlesByTimePeriod(context.Background(), since, 500, 7)
+	require.NoError(t, err)
+	assert.Empty(t, articles)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNormalizeTags(t *testing.T) {
+	assert.Equal(t, []string{"cs.lg", "machine learning"}, NormalizeTags([]string{" cs.LG", "Machine Learning", "", "CS.lg"}))
+	assert.Nil(t, NormalizeTags(nil))
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/samber/lo"
+
+	"neuro_scout_bot_v1/internal/canonical"
+	"neuro_scout_bot_v1/internal/fingerprint"
+)
+
+const backfillBatchSize = 500
+
+type dbBackfillArticle struct {
+	ID      int64          `db:"id"`
+	Title   string         `db:"title"`
+	Link    string         `db:"link"`
+	Summary sql.NullString `db:"summary"`
+	Content string         `db:"content"`
+}
+
+// BackfillArticles sets the canonical link and fingerprint of articles
+// stored before those existed, and returns how many were updated.
+func (s *ArticlePostgresStorage) BackfillArticles(ctx context.Context) (int, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var (
+		updated int
+		lastID  int64
+	)
+
+	for {
+		var articles []dbBackfillArticle
+		if err := conn.SelectContext(
+			ctx,
+			&articles,
+			`SELECT id, title, link, summary, content FROM articles
+				WHERE fingerprint IS NULL AND id > $1
+				ORDER BY id LIMIT $2;`,
+			lastID,
+			backfillBatchSize,
+		); err != nil {
+			return updated, err
+		}
+
+		for _, article := range articles {
+			lastID = article.ID
+
+			fp := fingerprint.Of(article.Title, lo.Ternary(article.Summary.String != "", article.Summary.String, article.Content))
+
+			var (
+				dbFingerprint sql.NullInt64
+				bands         []int32
+			)
+			if fp != 0 {
+				dbFingerprint = sql.NullInt64{Int64: int64(fp), Valid: true}
+				bands = fingerprint.Bands(fp)
+			}
+
+			// Another article may already hold the cleaned link.
+			if _, err := conn.ExecContext(
+				ctx,
+				`UPDATE articles SET
+					canonical_link = CASE
+						WHEN NOT EXISTS (SELECT 1 FROM articles other WHERE other.canonical_link = $1 AND other.id <> $4) THEN $1
+						ELSE canonical_link
+					END,
+					fingerprint = $2,
+					fingerprint_bands = $3
+				WHERE id = $4;`,
+				canonical.Clean(article.Link),
+				dbFingerprint,
+				pq.Array(bands),
+				article.ID,
+			); err != nil {
+				return updated, err
+			}
+
+			updated++
+		}
+
+		if len(articles) < backfillBatchSize {
+			return updated, nil
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArticlePostgresStorage_BackfillArticles(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	storage := NewArticleStorage(sqlx.NewDb(mockDB, "sqlmock"))
+
+	mock.ExpectQuery("SELECT (.+) FROM articles WHERE fingerprint IS NULL").
+		WithArgs(int64(0), backfillBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "link", "summary", "content"}).
+			AddRow(7, "Sparse attention", "http://www.example.com/sparse/?utm_source=rss", "Attention over fewer tokens", ""))
+	mock.ExpectExec("UPDATE articles SET canonical_link").
+		WithArgs("https://example.com/sparse", sqlmock.AnyArg(), sqlmock.AnyArg(), int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	updated, err := storage.BackfillArticles(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
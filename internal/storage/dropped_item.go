@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+type DroppedItemPostgresStorage struct {
+	db *sqlx.DB
+}
+
+func NewDroppedItemStorage(db *sqlx.DB) *DroppedItemPostgresStorage {
+	return &DroppedItemPostgresStorage{db: db}
+}
+
+// RecordDropped logs a dropped item. An item the same stage dropped before
+// has its reason, time and count updated.
+func (s *DroppedItemPostgresStorage) RecordDropped(ctx context.Context, item model.DroppedItem) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(
+		ctx,
+		`INSERT INTO dropped_items (source_id, title, link, stage, reason)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (source_id, link, stage) DO UPDATE SET
+				title = EXCLUDED.title,
+				reason = EXCLUDED.reason,
+				drop_count = dropped_items.drop_count + 1,
+				dropped_at = NOW()`,
+		item.SourceID, item.Title, item.Link, item.Stage, item.Reason,
+	); err != nil {
+		return fmt.Errorf("failed to record dropped item: %w", err)
+	}
+
+	return nil
+}
+
+// DroppedItems returns the latest dropped items, optionally only those of a
+// source or a stage.
+func (s *DroppedItemPostgresStorage) DroppedItems(ctx context.Context, sourceID int64, stage string, limit uint64) ([]model.DroppedItem, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	var items []dbDroppedItem
+	if err := conn.SelectContext(
+		ctx,
+		&items,
+		`SELECT id, source_id, title, link, stage, reason, drop_count, dropped_at FROM dropped_items
+			WHERE ($1 = 0 OR source_id = $1) AND ($2 = '' OR stage = $2)
+			ORDER BY dropped_at DESC, id DESC LIMIT $3`,
+		sourceID, stage, limit,
+	); err != nil {
+		return nil, fmt.Errorf("failed to select dropped items: %w", err)
+	}
+
+	return lo.Map(items, func(item dbDroppedItem, _ int) model.DroppedItem { return item.toModel() }), nil
+}
+
+type dbDroppedItem struct {
+	ID        int64     `db:"id"`
+	SourceID  int64     `db:"source_id"`
+	Title     string    `db:"title"`
+	Link      string    `db:"link"`
+	Stage     string    `db:"stage"`
+	Reason    string    `db:"reason"`
+	DropCount int       `db:"drop_count"`
+	DroppedAt time.Time `db:"dropped_at"`
+}
+
+func (i dbDroppedItem) toModel() model.DroppedItem {
+	return model.DroppedItem{
+		ID:        i.ID,
+		SourceID:  i.SourceID,
+		Title:     i.Title,
+		Link:      i.Link,
+		Stage:     i.Stage,
+		Reason:    i.Reason,
+		Count:     i.DropCount,
+		DroppedAt: i.DroppedAt,
+	}
+}
@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/samber/lo"
+
+	"neuro_scout_bot_v1/internal/fingerprint"
+	"neuro_scout_bot_v1/internal/model"
+)
+
+// FindDuplicate returns the stored article created since the given time
+// whose fingerprint is closest to fp, if it is within maxDistance.
+func (s *ArticlePostgresStorage) FindDuplicate(ctx context.Context, fp uint64, since time.Time, maxDistance int) (model.DuplicateMatch, bool, error) {
+	return s.findDuplicate(ctx, fp, since, maxDistance, 0, false)
+}
+
+// FindPostedDuplicate is FindDuplicate over the posted articles other than
+// the given one.
+func (s *ArticlePostgresStorage) FindPostedDuplicate(ctx context.Context, article model.Article, since time.Time, maxDistance int) (model.DuplicateMatch, bool, error) {
+	fp := article.Fingerprint
+	if fp == 0 {
+		fp = fingerprint.Of(article.Title, lo.Ternary(article.Summary != "", article.Summary, article.Content))
+	}
+
+	return s.findDuplicate(ctx, fp, since, maxDistance, article.ID, true)
+}
+
+// findDuplicate compares the fingerprints of the candidates that share a
+// band with the fingerprint.
+func (s *ArticlePostgresStorage) findDuplicate(
+	ctx context.Context,
+	fp uint64,
+	since time.Time,
+	maxDistance int,
+	excludeID int64,
+	postedOnly bool,
+) (model.DuplicateMatch, bool, error) {
+	if fp == 0 || maxDistance < 0 {
+		return model.DuplicateMatch{}, false, nil
+	}
+
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return model.DuplicateMatch{}, false, err
+	}
+	defer conn.Close()
+
+	bands := fingerprint.Bands(fp)
+	useBands := maxDistance < len(bands)
+
+	var candidates []dbFingerprintCandidate
+
+	if err := conn.SelectContext(
+		ctx,
+		&candidates,
+		`SELECT id, title, link, fingerprint FROM articles
+			WHERE fingerprint IS NOT NULL
+				AND created_at >= $1::timestamp
+				AND (NOT $2 OR fingerprint_bands && $3::integer[])
+				AND id <> $4
+				AND (NOT $5 OR posted_at IS NOT NULL);`,
+		since.UTC().Format(time.RFC3339),
+		useBands,
+		pq.Array(bands),
+		excludeID,
+		postedOnly,
+	); err != nil {
+		return model.DuplicateMatch{}, false, err
+	}
+
+	var (
+		match model.DuplicateMatch
+		found bool
+	)
+	for _, candidate := range candidates {
+		distance := fingerprint.Distance(fp, uint64(candidate.Fingerprint))
+		if distance > maxDistance || (found && distance >= match.Distance) {
+			continue
+		}
+
+		match = model.DuplicateMatch{
+			ArticleID: candidate.ID,
+			Title:     candidate.Title,
+			Link:      candidate.Link,
+			Distance:  distance,
+		}
+		found = true
+	}
+
+	return match, found, nil
+}
+
+// RecordDuplicate logs an item that was found to repeat a stored article.
+func (s *ArticlePostgresStorage) RecordDuplicate(ctx context.Context, duplicate model.Duplicate) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(
+		ctx,
+		`INSERT INTO article_duplicates (article_id, source_id, title, link, distance)
+			VALUES ($1, $2, $3, $4, $5);`,
+		duplicate.Match.ArticleID,
+		duplicate.SourceID,
+		duplicate.Title,
+		duplicate.Link,
+		duplicate.Match.Distance,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RecentDuplicates returns the latest recorded duplicates with the articles
+// they matched.
+func (s *ArticlePostgresStorage) RecentDuplicates(ctx context.Context, limit uint64) ([]model.Duplicate, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var duplicates []dbDuplicate
+
+	if err := conn.SelectContext(
+		ctx,
+		&duplicates,
+		`SELECT d.id, d.source_id, d.title, d.link, d.distance, d.detected_at,
+				a.id AS article_id, a.title AS article_title, a.link AS article_link
+			FROM article_duplicates d JOIN articles a ON a.id = d.article_id
+			ORDER BY d.detected_at DESC, d.id DESC LIMIT $1;`,
+		limit,
+	); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(duplicates, func(duplicate dbDuplicate, _ int) model.Duplicate {
+		return model.Duplicate{
+			ID:       duplicate.ID,
+			SourceID: duplicate.SourceID,
+			Title:    duplicate.Title,
+			Link:     duplicate.Link,
+			Match: model.DuplicateMatch{
+				ArticleID: duplicate.ArticleID,
+				Title:     duplicate.ArticleTitle,
+				Link:      duplicate.ArticleLink,
+				Distance:  duplicate.Distance,
+			},
+			DetectedAt: duplicate.DetectedAt,
+		}
+	}), nil
+}
+
+type dbFingerprintCandidate struct {
+	ID          int64  `db:"id"`
+	Title       string `db:"title"`
+	Link        string `db:"link"`
+	Fingerprint int64  `db:"fingerprint"`
+}
+
+type dbDuplicate struct {
+	ID           int64     `db:"id"`
+	SourceID     int64     `db:"source_id"`
+	Title        string    `db:"title"`
+	Link         string    `db:"link"`
+	Distance     int       `db:"distance"`
+	DetectedAt   time.Time `db:"detected_at"`
+	ArticleID    int64     `db:"article_id"`
+	ArticleTitle string    `db:"article_title"`
+	ArticleLink  string    `db:"article_link"`
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArticlePostgresStorage_FindDuplicate(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	storage := NewArticleStorage(sqlx.NewDb(mockDB, "sqlmock"))
+
+	const fp = uint64(0b1111_0000)
+
+	mock.ExpectQuery("SELECT id, title, link, fingerprint FROM articles").
+		WithArgs(sqlmock.AnyArg(), true, sqlmock.AnyArg(), int64(0), false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "link", "fingerprint"}).
+			AddRow(1, "Far", "https://example.com/far", int64(0b0000_1111)).
+			AddRow(2, "Near", "https://example.com/near", int64(0b1111_0011)).
+			AddRow(3, "Nearest", "https://example.com/nearest", int64(0b1111_0001)))
+
+	match, found, err := storage.FindDuplicate(context.Background(), fp, time.Now().Add(-time.Hour), 3)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, int64(3), match.ArticleID)
+	assert.Equal(t, "Nearest", match.Title)
+	assert.Equal(t, 1, match.Distance)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestArticlePostgresStorage_FindDuplicateWithoutFingerprint(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	storage := NewArticleStorage(sqlx.NewDb(mockDB, "sqlmock"))
+
+	_, found, err := storage.FindDuplicate(context.Background(), 0, time.Now(), 6)
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
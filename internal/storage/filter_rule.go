@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+type FilterRulePostgresStorage struct {
+	db *sqlx.DB
+}
+
+func NewFilterRuleStorage(db *sqlx.DB) *FilterRulePostgresStorage {
+	return &FilterRulePostgresStorage{db: db}
+}
+
+// FilterRules returns all rules, global ones first.
+func (s *FilterRulePostgresStorage) FilterRules(ctx context.Context) ([]model.FilterRule, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	var rules []dbFilterRule
+	if err := conn.SelectContext(
+		ctx,
+		&rules,
+		"SELECT id, source_id, action, match_type, pattern, created_at FROM filter_rules ORDER BY source_id NULLS FIRST, id",
+	); err != nil {
+		return nil, fmt.Errorf("failed to select filter rules: %w", err)
+	}
+
+	return lo.Map(rules, func(rule dbFilterRule, _ int) model.FilterRule { return rule.toModel() }), nil
+}
+
+// AddFilterRule stores the rule and returns its ID.
+func (s *FilterRulePostgresStorage) AddFilterRule(ctx context.Context, rule model.FilterRule) (int64, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	var id int64
+	if err := conn.QueryRowxContext(
+		ctx,
+		"INSERT INTO filter_rules (source_id, action, match_type, pattern) VALUES ($1, $2, $3, $4) RETURNING id",
+		sql.NullInt64{Int64: rule.SourceID, Valid: rule.SourceID != 0}, rule.Action, rule.Match, rule.Pattern,
+	).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to insert filter rule: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *FilterRulePostgresStorage) DeleteFilterRule(ctx context.Context, id int64) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	result, err := conn.ExecContext(ctx, "DELETE FROM filter_rules WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete filter rule: %w", err)
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("filter rule %d not found", id)
+	}
+
+	return nil
+}
+
+type dbFilterRule struct {
+	ID        int64         `db:"id"`
+	SourceID  sql.NullInt64 `db:"source_id"`
+	Action    string        `db:"action"`
+	Match     string        `db:"match_type"`
+	Pattern   string        `db:"pattern"`
+	CreatedAt time.Time     `db:"created_at"`
+}
+
+func (r dbFilterRule) toModel() model.FilterRule {
+	return model.FilterRule{
+		ID:        r.ID,
+		SourceID:  r.SourceID.Int64,
+		Action:    r.Action,
+		Match:     r.Match,
+		Pattern:   r.Pattern,
+		CreatedAt: r.CreatedAt,
+	}
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/secret"
+)
+
+// dbHTTPOptions is the part of a source's HTTP options stored in the clear.
+type dbHTTPOptions struct {
+	UserAgent          string `json:"user_agent,omitempty"`
+	Username           string `json:"username,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+	TimeoutSeconds     int64  `json:"timeout_seconds,omitempty"`
+}
+
+// dbHTTPSecrets is the part of a source's HTTP options stored encrypted.
+type dbHTTPSecrets struct {
+	Headers     map[string]string `json:"headers,omitempty"`
+	Password    string            `json:"password,omitempty"`
+	BearerToken string            `json:"bearer_token,omitempty"`
+	Cookies     map[string]string `json:"cookies,omitempty"`
+	ProxyURL    string            `json:"proxy_url,omitempty"`
+}
+
+func (s dbHTTPSecrets) isZero() bool {
+	return len(s.Headers) == 0 && s.Password == "" && s.BearerToken == "" && len(s.Cookies) == 0 && s.ProxyURL == ""
+}
+
+// SetHTTPOptions replaces the HTTP options of the source. The secrets among
+// them are encrypted, so they can only be set with a secret key configured.
+func (s *SourcePostgresStorage) SetHTTPOptions(ctx context.Context, sourceID int64, opts model.HTTPOptions) error {
+	options, err := json.Marshal(dbHTTPOptions{
+		UserAgent:          opts.UserAgent,
+		Username:           opts.Username,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+		TimeoutSeconds:     int64(opts.Timeout / time.Second),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode source HTTP options: %w", err)
+	}
+
+	secrets := dbHTTPSecrets{
+		Headers:     opts.Headers,
+		Password:    opts.Password,
+		BearerToken: opts.BearerToken,
+		Cookies:     opts.Cookies,
+		ProxyURL:    opts.ProxyURL,
+	}
+
+	var sealed string
+	if !secrets.isZero() {
+		plaintext, err := json.Marshal(secrets)
+		if err != nil {
+			return fmt.Errorf("failed to encode source HTTP secrets: %w", err)
+		}
+
+		if sealed, err = s.secrets.Seal(plaintext); err != nil {
+			return fmt.Errorf("failed to encrypt source HTTP secrets: %w", err)
+		}
+	}
+
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(
+		ctx,
+		"UPDATE sources SET http_options = $1, http_secrets = $2 WHERE id = $3",
+		options, sealed, sourceID,
+	); err != nil {
+		return fmt.Errorf("failed to update source HTTP options: %w", err)
+	}
+
+	return nil
+}
+
+// httpOptions decodes the stored options. Secrets that cannot be decrypted
+// are left out, so the source is fetched without them and fails visibly.
+func (s dbSource) httpOptions(box *secret.Box) model.HTTPOptions {
+	var options dbHTTPOptions
+	if len(s.HTTPOptions) > 0 {
+		if err := json.Unmarshal(s.HTTPOptions, &options); err != nil {
+			log.Printf("[WARN] failed to decode HTTP options of source %d: %v", s.ID, err)
+		}
+	}
+
+	var secrets dbHTTPSecrets
+	if s.HTTPSecrets != "" {
+		plaintext, err := box.Open(s.HTTPSecrets)
+		if err == nil {
+			err = json.Unmarshal(plaintext, &secrets)
+		}
+		if err != nil {
+			log.Printf("[WARN] failed to read HTTP secrets of source %d: %v", s.ID, err)
+		}
+	}
+
+	return model.HTTPOptions{
+		UserAgent:          options.UserAgent,
+		Headers:            secrets.Headers,
+		Username:           options.Username,
+		Password:           secrets.Password,
+		BearerToken:        secrets.BearerToken,
+		Cookies:            secrets.Cookies,
+		ProxyURL:           secrets.ProxyURL,
+		InsecureSkipVerify: options.InsecureSkipVerify,
+		Timeout:            time.Duration(options.TimeoutSeconds) * time.Second,
+	}
+}
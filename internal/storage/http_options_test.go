@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/secret"
+)
+
+// captureArg is a sqlmock argument that keeps the value it was given.
+type captureArg struct {
+	value driver.Value
+}
+
+func (c *captureArg) Match(v driver.Value) bool {
+	c.value = v
+	return true
+}
+
+func TestSourcePostgresStorage_SetHTTPOptions(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	box, err := secret.NewBox("a long random secret key")
+	require.NoError(t, err)
+
+	storage := NewSourceStorage(sqlx.NewDb(mockDB, "sqlmock"), box)
+
+	opts := model.HTTPOptions{
+		UserAgent: "NeuroScout/1.0",
+		Username:  "user",
+		Password:  "hunter2",
+		Headers:   map[string]string{"X-Api-Key": "key"},
+		Timeout:   45 * time.Second,
+		ProxyURL:  "http://proxy.internal:3128",
+	}
+
+	options, secrets := &captureArg{}, &captureArg{}
+	mock.ExpectExec("UPDATE sources SET http_options = \\$1, http_secrets = \\$2 WHERE id = \\$3").
+		WithArgs(options, secrets, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, storage.SetHTTPOptions(context.Background(), 1, opts))
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	assert.NotContains(t, string(options.value.([]byte)), "hunter2")
+	assert.NotContains(t, secrets.value.(string), "hunter2")
+
+	source := dbSource{ID: 1, HTTPOptions: options.value.([]byte), HTTPSecrets: secrets.value.(string)}
+	assert.Equal(t, opts, source.httpOptions(box))
+
+	withoutKey := source.httpOptions(nil)
+	assert.Equal(t, "user", withoutKey.Username)
+	assert.Empty(t, withoutKey.Password, "secrets are left out without the key")
+
+	// Secrets cannot be stored in the clear.
+	err = NewSourceStorage(sqlx.NewDb(mockDB, "sqlmock"), nil).SetHTTPOptions(context.Background(), 1, opts)
+	assert.ErrorIs(t, err, secret.ErrNoKey)
+}
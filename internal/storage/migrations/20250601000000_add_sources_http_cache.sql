@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sources
+    ADD COLUMN etag          VARCHAR(255),
+    ADD COLUMN last_modified VARCHAR(255);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sources
+    DROP COLUMN IF EXISTS etag,
+    DROP COLUMN IF EXISTS last_modified;
+-- +goose StatementEnd
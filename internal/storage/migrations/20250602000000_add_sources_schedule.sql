@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sources
+    ADD COLUMN fetch_interval_seconds INT,
+    ADD COLUMN next_fetch_at          TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sources
+    DROP COLUMN IF EXISTS fetch_interval_seconds,
+    DROP COLUMN IF EXISTS next_fetch_at;
+-- +goose StatementEnd
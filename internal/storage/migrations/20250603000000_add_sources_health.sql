@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sources
+    ADD COLUMN last_success_at      TIMESTAMP,
+    ADD COLUMN last_error_at        TIMESTAMP,
+    ADD COLUMN last_error           TEXT,
+    ADD COLUMN consecutive_failures INT     NOT NULL DEFAULT 0,
+    ADD COLUMN last_items_count     INT     NOT NULL DEFAULT 0,
+    ADD COLUMN last_new_item_at     TIMESTAMP,
+    ADD COLUMN disabled             BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN disabled_reason      TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sources
+    DROP COLUMN IF EXISTS last_success_at,
+    DROP COLUMN IF EXISTS last_error_at,
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS consecutive_failures,
+    DROP COLUMN IF EXISTS last_items_count,
+    DROP COLUMN IF EXISTS last_new_item_at,
+    DROP COLUMN IF EXISTS disabled,
+    DROP COLUMN IF EXISTS disabled_reason;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sources
+    ADD COLUMN kind   VARCHAR(32) NOT NULL DEFAULT 'rss',
+    ADD COLUMN config JSONB       NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sources
+    DROP COLUMN IF EXISTS kind,
+    DROP COLUMN IF EXISTS config;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles
+    ADD COLUMN authors          TEXT[]      DEFAULT '{}',
+    ADD COLUMN primary_category VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN categories       TEXT[]      DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE articles
+    DROP COLUMN IF EXISTS authors,
+    DROP COLUMN IF EXISTS primary_category,
+    DROP COLUMN IF EXISTS categories;
+-- +goose StatementEnd
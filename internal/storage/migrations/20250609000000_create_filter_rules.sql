@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE filter_rules
+(
+    id         SERIAL PRIMARY KEY,
+    source_id  INT REFERENCES sources (id) ON DELETE CASCADE,
+    action     VARCHAR(16)  NOT NULL,
+    match_type VARCHAR(16)  NOT NULL,
+    pattern    TEXT         NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS filter_rules;
+-- +goose StatementEnd
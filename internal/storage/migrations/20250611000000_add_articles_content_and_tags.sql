@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles
+    ADD COLUMN guid              TEXT      NOT NULL DEFAULT '',
+    ADD COLUMN image_url         TEXT      NOT NULL DEFAULT '',
+    ADD COLUMN content           TEXT      NOT NULL DEFAULT '',
+    ADD COLUMN source_updated_at TIMESTAMP NULL;
+
+CREATE TABLE tags
+(
+    id   SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE
+);
+
+CREATE TABLE article_tags
+(
+    article_id INTEGER NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+    tag_id     INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (article_id, tag_id)
+);
+
+CREATE INDEX article_tags_tag_id_idx ON article_tags (tag_id);
+
+INSERT INTO tags (name)
+SELECT DISTINCT LOWER(TRIM(c.category))
+FROM articles a
+CROSS JOIN LATERAL UNNEST(a.categories) AS c(category)
+WHERE TRIM(c.category) <> ''
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO article_tags (article_id, tag_id)
+SELECT DISTINCT a.id, t.id
+FROM articles a
+CROSS JOIN LATERAL UNNEST(a.categories) AS c(category)
+JOIN tags t ON t.name = LOWER(TRIM(c.category))
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS article_tags;
+DROP TABLE IF EXISTS tags;
+
+ALTER TABLE articles
+    DROP COLUMN IF EXISTS guid,
+    DROP COLUMN IF EXISTS image_url,
+    DROP COLUMN IF EXISTS content,
+    DROP COLUMN IF EXISTS source_updated_at;
+-- +goose StatementEnd
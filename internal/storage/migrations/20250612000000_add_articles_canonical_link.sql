@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles
+    ADD COLUMN canonical_link TEXT NOT NULL DEFAULT '';
+
+UPDATE articles SET canonical_link = link;
+
+ALTER TABLE articles DROP CONSTRAINT IF EXISTS articles_link_key;
+
+CREATE INDEX articles_link_idx ON articles (link);
+CREATE UNIQUE INDEX articles_canonical_link_key ON articles (canonical_link);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS articles_canonical_link_key;
+DROP INDEX IF EXISTS articles_link_idx;
+
+ALTER TABLE articles ADD CONSTRAINT articles_link_key UNIQUE (link);
+
+ALTER TABLE articles
+    DROP COLUMN IF EXISTS canonical_link;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles
+    ADD COLUMN fingerprint       BIGINT    NULL,
+    ADD COLUMN fingerprint_bands INTEGER[] NULL;
+
+CREATE INDEX articles_fingerprint_bands_idx ON articles USING GIN (fingerprint_bands);
+CREATE INDEX articles_created_at_idx ON articles (created_at);
+
+CREATE TABLE article_duplicates
+(
+    id          SERIAL PRIMARY KEY,
+    article_id  INTEGER      NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+    source_id   INTEGER      NOT NULL,
+    title       VARCHAR(255) NOT NULL,
+    link        TEXT         NOT NULL,
+    distance    SMALLINT     NOT NULL,
+    detected_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX article_duplicates_detected_at_idx ON article_duplicates (detected_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS article_duplicates;
+
+DROP INDEX IF EXISTS articles_created_at_idx;
+DROP INDEX IF EXISTS articles_fingerprint_bands_idx;
+
+ALTER TABLE articles
+    DROP COLUMN IF EXISTS fingerprint,
+    DROP COLUMN IF EXISTS fingerprint_bands;
+-- +goose StatementEnd
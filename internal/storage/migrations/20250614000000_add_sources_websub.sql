@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sources
+    ADD COLUMN websub_hub              TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN websub_topic            TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN websub_secret           TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN websub_state            VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN websub_requested_at     TIMESTAMP   NULL,
+    ADD COLUMN websub_verified_at      TIMESTAMP   NULL,
+    ADD COLUMN websub_lease_expires_at TIMESTAMP   NULL,
+    ADD COLUMN websub_last_push_at     TIMESTAMP   NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sources
+    DROP COLUMN IF EXISTS websub_hub,
+    DROP COLUMN IF EXISTS websub_topic,
+    DROP COLUMN IF EXISTS websub_secret,
+    DROP COLUMN IF EXISTS websub_state,
+    DROP COLUMN IF EXISTS websub_requested_at,
+    DROP COLUMN IF EXISTS websub_verified_at,
+    DROP COLUMN IF EXISTS websub_lease_expires_at,
+    DROP COLUMN IF EXISTS websub_last_push_at;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE dropped_items
+(
+    id         SERIAL PRIMARY KEY,
+    source_id  INTEGER     NOT NULL REFERENCES sources (id) ON DELETE CASCADE,
+    title      TEXT        NOT NULL,
+    link       TEXT        NOT NULL,
+    stage      VARCHAR(32) NOT NULL,
+    reason     TEXT        NOT NULL,
+    drop_count INTEGER     NOT NULL DEFAULT 1,
+    dropped_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (source_id, link, stage)
+);
+
+CREATE INDEX dropped_items_dropped_at_idx ON dropped_items (dropped_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS dropped_items;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles
+    ADD COLUMN body_text           TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN lead_image_url      TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN extraction_status   VARCHAR(16) NOT NULL DEFAULT 'pending',
+    ADD COLUMN extraction_attempts INTEGER     NOT NULL DEFAULT 0,
+    ADD COLUMN extraction_error    TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN extract_after       TIMESTAMP   NULL,
+    ADD COLUMN extracted_at        TIMESTAMP   NULL;
+
+-- Articles that were already posted are not worth extracting.
+UPDATE articles SET extraction_status = 'skipped' WHERE posted_at IS NOT NULL;
+
+CREATE INDEX articles_extraction_pending_idx ON articles (created_at) WHERE extraction_status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS articles_extraction_pending_idx;
+
+ALTER TABLE articles
+    DROP COLUMN IF EXISTS body_text,
+    DROP COLUMN IF EXISTS lead_image_url,
+    DROP COLUMN IF EXISTS extraction_status,
+    DROP COLUMN IF EXISTS extraction_attempts,
+    DROP COLUMN IF EXISTS extraction_error,
+    DROP COLUMN IF EXISTS extract_after,
+    DROP COLUMN IF EXISTS extracted_at;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sources
+    ADD COLUMN throttle_count    INTEGER   NOT NULL DEFAULT 0,
+    ADD COLUMN last_throttled_at TIMESTAMP NULL,
+    ADD COLUMN throttled_until   TIMESTAMP NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sources
+    DROP COLUMN IF EXISTS throttle_count,
+    DROP COLUMN IF EXISTS last_throttled_at,
+    DROP COLUMN IF EXISTS throttled_until;
+-- +goose StatementEnd
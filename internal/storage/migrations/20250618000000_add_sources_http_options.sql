@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sources
+    ADD COLUMN http_options JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN http_secrets TEXT  NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sources
+    DROP COLUMN IF EXISTS http_options,
+    DROP COLUMN IF EXISTS http_secrets;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles
+    ALTER COLUMN title TYPE TEXT,
+    ALTER COLUMN link TYPE TEXT;
+
+ALTER TABLE article_duplicates
+    ALTER COLUMN title TYPE TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE article_duplicates
+    ALTER COLUMN title TYPE VARCHAR(255) USING LEFT(title, 255);
+
+ALTER TABLE articles
+    ALTER COLUMN title TYPE VARCHAR(255) USING LEFT(title, 255),
+    ALTER COLUMN link TYPE VARCHAR(255) USING LEFT(link, 255);
+-- +goose StatementEnd
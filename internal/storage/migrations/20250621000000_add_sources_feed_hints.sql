@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sources
+    ADD COLUMN feed_interval_seconds INTEGER   NULL,
+    ADD COLUMN feed_skip_hours       INTEGER[] NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sources
+    DROP COLUMN IF EXISTS feed_interval_seconds,
+    DROP COLUMN IF EXISTS feed_skip_hours;
+-- +goose StatementEnd
@@ -2,19 +2,26 @@ package storage
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"neuro_scout_bot_v1/internal/model"
+	"neuro_scout_bot_v1/internal/secret"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type SourcePostgresStorage struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	secrets *secret.Box
 }
 
-func NewSourceStorage(db *sqlx.DB) *SourcePostgresStorage {
-	return &SourcePostgresStorage{db: db}
+// NewSourceStorage returns the source storage. secrets encrypts the secret
+// HTTP options of sources; without it they cannot be set.
+func NewSourceStorage(db *sqlx.DB, secrets *secret.Box) *SourcePostgresStorage {
+	return &SourcePostgresStorage{db: db, secrets: secrets}
 }
 
 type SourcesParams struct {
@@ -43,18 +50,7 @@ func (s *SourcePostgresStorage) Sources(ctx context.Context) ([]model.Source, er
 
 	result := make([]model.Source, 0, len(sources))
 	for _, source := range sources {
-		addedAt, err := time.Parse(time.RFC3339, source.CreatedAt)
-		if err != nil {
-			addedAt = time.Time{}
-		}
-
-		result = append(result, model.Source{
-			ID:        source.ID,
-			Name:      source.Name,
-			FeedURL:   source.FeedURL,
-			Priority:  source.Priority,
-			CreatedAt: addedAt,
-		})
+		result = append(result, source.toModel(s.secrets))
 	}
 
 	return result, nil
@@ -72,18 +68,7 @@ func (s *SourcePostgresStorage) SourceById(ctx context.Context, id int64) (model
 		return model.Source{}, fmt.Errorf("failed to get source by id: %w", err)
 	}
 
-	addedAt, err := time.Parse(time.RFC3339, source.CreatedAt)
-	if err != nil {
-		addedAt = time.Time{}
-	}
-
-	return model.Source{
-		ID:        source.ID,
-		Name:      source.Name,
-		FeedURL:   source.FeedURL,
-		Priority:  source.Priority,
-		CreatedAt: addedAt,
-	}, nil
+	return source.toModel(s.secrets), nil
 }
 
 func (s *SourcePostgresStorage) SourceByID(ctx context.Context, id int64) (*model.Source, error) {
@@ -98,34 +83,331 @@ func (s *SourcePostgresStorage) SourceByID(ctx context.Context, id int64) (*mode
 		return nil, fmt.Errorf("failed to get source by id: %w", err)
 	}
 
-	addedAt, err := time.Parse(time.RFC3339, source.CreatedAt)
+	result := source.toModel(s.secrets)
+	return &result, nil
+}
+
+func (s *SourcePostgresStorage) SetPriority(ctx context.Context, sourceID int64, priority int) error {
+	conn, err := s.db.Connx(ctx)
 	if err != nil {
-		addedAt = time.Time{}
+		return fmt.Errorf("failed to connect to database: %w", err)
 	}
+	defer conn.Close()
 
-	return &model.Source{
-		ID:        source.ID,
-		Name:      source.Name,
-		FeedURL:   source.FeedURL,
-		Priority:  source.Priority,
-		CreatedAt: addedAt,
-	}, nil
+	res, err := conn.ExecContext(ctx, "UPDATE sources SET priority = $1 WHERE id = $2", priority, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to update source priority: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("source %d not found", sourceID)
+	}
+
+	return nil
 }
 
-func (s *SourcePostgresStorage) SetPriority(ctx context.Context, sourceID int64, priority int) error {
+// UpdateCacheValidators stores the ETag and Last-Modified values, and the
+// feed hints a conditional request does not return.
+func (s *SourcePostgresStorage) UpdateCacheValidators(ctx context.Context, sourceID int64, etag, lastModified string, hints model.FeedHints) error {
 	conn, err := s.db.Connx(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	defer conn.Close()
 
-	if _, err := conn.ExecContext(ctx, "UPDATE sources SET priority = $1 WHERE id = $2", priority, sourceID); err != nil {
-		return fmt.Errorf("failed to update source priority: %w", err)
+	skipHours := make([]int64, len(hints.SkipHours))
+	for i, hour := range hints.SkipHours {
+		skipHours[i] = int64(hour)
+	}
+
+	if _, err := conn.ExecContext(
+		ctx,
+		`UPDATE sources SET etag = $1, last_modified = $2, feed_interval_seconds = $3, feed_skip_hours = $4
+		WHERE id = $5`,
+		etag, lastModified,
+		sql.NullInt64{Int64: int64(hints.Interval / time.Second), Valid: hints.Interval > 0},
+		pq.Int64Array(skipHours),
+		sourceID,
+	); err != nil {
+		return fmt.Errorf("failed to update source cache validators: %w", err)
+	}
+
+	return nil
+}
+
+// SetNextFetchAt schedules the next fetch of the source.
+func (s *SourcePostgresStorage) SetNextFetchAt(ctx context.Context, sourceID int64, at time.Time) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(
+		ctx,
+		"UPDATE sources SET next_fetch_at = $1 WHERE id = $2",
+		at.UTC(), sourceID,
+	); err != nil {
+		return fmt.Errorf("failed to update source next fetch time: %w", err)
 	}
 
 	return nil
 }
 
+// SetFetchInterval sets the source's own fetch interval, zero for the
+// default, and makes the source due immediately.
+func (s *SourcePostgresStorage) SetFetchInterval(ctx context.Context, sourceID int64, interval time.Duration) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	seconds := sql.NullInt64{
+		Int64: int64(interval / time.Second),
+		Valid: interval > 0,
+	}
+
+	res, err := conn.ExecContext(
+		ctx,
+		"UPDATE sources SET fetch_interval_seconds = $1, next_fetch_at = NULL WHERE id = $2",
+		seconds, sourceID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update source fetch interval: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("source %d not found", sourceID)
+	}
+
+	return nil
+}
+
+// RecordFetchSuccess resets the failure counter and stores the number of
+// fetched items. newItemsCount is the number of items that were stored.
+func (s *SourcePostgresStorage) RecordFetchSuccess(ctx context.Context, sourceID int64, itemsCount, newItemsCount int) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(
+		ctx,
+		`UPDATE sources SET
+			last_success_at = NOW(),
+			consecutive_failures = 0,
+			last_items_count = $1,
+			last_new_item_at = CASE WHEN $2 > 0 THEN NOW() ELSE last_new_item_at END
+		WHERE id = $3`,
+		itemsCount, newItemsCount, sourceID,
+	); err != nil {
+		return fmt.Errorf("failed to record source fetch success: %w", err)
+	}
+
+	return nil
+}
+
+// RecordFetchFailure stores the error and returns the number of consecutive
+// failures including this one.
+func (s *SourcePostgresStorage) RecordFetchFailure(ctx context.Context, sourceID int64, fetchErr string) (int, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	var failures int
+	if err := conn.QueryRowxContext(
+		ctx,
+		`UPDATE sources SET
+			last_error = $1,
+			last_error_at = NOW(),
+			consecutive_failures = consecutive_failures + 1
+		WHERE id = $2
+		RETURNING consecutive_failures`,
+		fetchErr, sourceID,
+	).Scan(&failures); err != nil {
+		return 0, fmt.Errorf("failed to record source fetch failure: %w", err)
+	}
+
+	return failures, nil
+}
+
+// RecordThrottle counts a fetch the source's host refused because of the
+// request rate, and stores until when the host asked to be left alone.
+func (s *SourcePostgresStorage) RecordThrottle(ctx context.Context, sourceID int64, until time.Time) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(
+		ctx,
+		`UPDATE sources SET
+			throttle_count = throttle_count + 1,
+			last_throttled_at = NOW(),
+			throttled_until = $1
+		WHERE id = $2`,
+		until.UTC(), sourceID,
+	); err != nil {
+		return fmt.Errorf("failed to record source throttling: %w", err)
+	}
+
+	return nil
+}
+
+// Disable stops the source from being fetched until it is enabled again.
+func (s *SourcePostgresStorage) Disable(ctx context.Context, sourceID int64, reason string) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(
+		ctx,
+		"UPDATE sources SET disabled = TRUE, disabled_reason = $1 WHERE id = $2",
+		reason, sourceID,
+	); err != nil {
+		return fmt.Errorf("failed to disable source: %w", err)
+	}
+
+	return nil
+}
+
+// Enable re-enables a disabled source, resets its health and makes it due
+// immediately.
+func (s *SourcePostgresStorage) Enable(ctx context.Context, sourceID int64) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	res, err := conn.ExecContext(
+		ctx,
+		`UPDATE sources SET
+			disabled = FALSE,
+			disabled_reason = NULL,
+			consecutive_failures = 0,
+			next_fetch_at = NULL,
+			last_new_item_at = NOW()
+		WHERE id = $1`,
+		sourceID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enable source: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("source %d not found", sourceID)
+	}
+
+	return nil
+}
+
+// SourceByChannelID returns the source linked to the Telegram channel, or nil
+// if no source is linked to it.
+func (s *SourcePostgresStorage) SourceByChannelID(ctx context.Context, chatID int64) (*model.Source, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	var source dbSource
+	if err := conn.GetContext(ctx, &source, "SELECT * FROM sources WHERE telegram_chat_id = $1", chatID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get source by channel id: %w", err)
+	}
+
+	result := source.toModel(s.secrets)
+	return &result, nil
+}
+
+// LinkChannel links a Telegram channel to the source. A zero chatID unlinks
+// the source, which makes it polled again.
+func (s *SourcePostgresStorage) LinkChannel(ctx context.Context, sourceID int64, chatID int64) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	res, err := conn.ExecContext(
+		ctx,
+		"UPDATE sources SET telegram_chat_id = $1 WHERE id = $2",
+		sql.NullInt64{Int64: chatID, Valid: chatID != 0}, sourceID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to link channel to source: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("source %d not found", sourceID)
+	}
+
+	return nil
+}
+
+// SetFilter stores the source's filter expression. An empty expression
+// removes the filter.
+func (s *SourcePostgresStorage) SetFilter(ctx context.Context, sourceID int64, expr string) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	res, err := conn.ExecContext(ctx, "UPDATE sources SET filter_expression = $1 WHERE id = $2", expr, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to update source filter: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("source %d not found", sourceID)
+	}
+
+	return nil
+}
+
+const manualSourceName = "Manual submissions"
+
+// ManualSource returns the source that holds articles submitted by hand,
+// creating it on first use.
+func (s *SourcePostgresStorage) ManualSource(ctx context.Context) (model.Source, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return model.Source{}, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	var source dbSource
+	err = conn.GetContext(ctx, &source, "SELECT * FROM sources WHERE kind = $1 ORDER BY id LIMIT 1", model.SourceKindManual)
+	if err == nil {
+		return source.toModel(s.secrets), nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return model.Source{}, fmt.Errorf("failed to get manual source: %w", err)
+	}
+
+	if err := conn.GetContext(
+		ctx,
+		&source,
+		"INSERT INTO sources (name, feed_url, priority, kind, config, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING *",
+		manualSourceName, "", 0, model.SourceKindManual, []byte("{}"), time.Now().UTC(),
+	); err != nil {
+		return model.Source{}, fmt.Errorf("failed to create manual source: %w", err)
+	}
+
+	return source.toModel(s.secrets), nil
+}
+
 func (s *SourcePostgresStorage) Add(ctx context.Context, source model.Source) (int64, error) {
 	conn, err := s.db.Connx(ctx)
 	if err != nil {
@@ -133,11 +415,21 @@ func (s *SourcePostgresStorage) Add(ctx context.Context, source model.Source) (i
 	}
 	defer conn.Close()
 
+	kind := source.Kind
+	if kind == "" {
+		kind = model.SourceKindRSS
+	}
+
+	config := []byte(source.Config)
+	if len(config) == 0 {
+		config = []byte("{}")
+	}
+
 	var id int64
 	err = conn.QueryRowxContext(
 		ctx,
-		"INSERT INTO sources (name, feed_url, priority, created_at) VALUES ($1, $2, $3, $4) RETURNING id",
-		source.Name, source.FeedURL, source.Priority, source.CreatedAt,
+		"INSERT INTO sources (name, feed_url, priority, group_name, kind, config, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		source.Name, source.FeedURL, source.Priority, source.Group, kind, config, source.CreatedAt,
 	).Scan(&id)
 
 	if err != nil {
@@ -162,9 +454,99 @@ func (s *SourcePostgresStorage) Delete(ctx context.Context, id int64) error {
 }
 
 type dbSource struct {
-	ID        int64  `db:"id"`
-	Name      string `db:"name"`
-	FeedURL   string `db:"feed_url"`
-	Priority  int64  `db:"priority"`
-	CreatedAt string `db:"created_at"`
+	ID                   int64          `db:"id"`
+	Name                 string         `db:"name"`
+	FeedURL              string         `db:"feed_url"`
+	Priority             int64          `db:"priority"`
+	GroupName            string         `db:"group_name"`
+	Kind                 string         `db:"kind"`
+	Config               []byte         `db:"config"`
+	ETag                 sql.NullString `db:"etag"`
+	LastModified         sql.NullString `db:"last_modified"`
+	FetchIntervalSeconds sql.NullInt64  `db:"fetch_interval_seconds"`
+	FeedIntervalSeconds  sql.NullInt64  `db:"feed_interval_seconds"`
+	FeedSkipHours        pq.Int64Array  `db:"feed_skip_hours"`
+	NextFetchAt          sql.NullTime   `db:"next_fetch_at"`
+	LastSuccessAt        sql.NullTime   `db:"last_success_at"`
+	LastErrorAt          sql.NullTime   `db:"last_error_at"`
+	LastError            sql.NullString `db:"last_error"`
+	ConsecutiveFailures  int            `db:"consecutive_failures"`
+	LastItemsCount       int            `db:"last_items_count"`
+	LastNewItemAt        sql.NullTime   `db:"last_new_item_at"`
+	ThrottleCount        int            `db:"throttle_count"`
+	LastThrottledAt      sql.NullTime   `db:"last_throttled_at"`
+	ThrottledUntil       sql.NullTime   `db:"throttled_until"`
+	Disabled             bool           `db:"disabled"`
+	DisabledReason       sql.NullString `db:"disabled_reason"`
+	TelegramChatID       sql.NullInt64  `db:"telegram_chat_id"`
+	FilterExpression     string         `db:"filter_expression"`
+	WebSubHub            string         `db:"websub_hub"`
+	WebSubTopic          string         `db:"websub_topic"`
+	WebSubSecret         string         `db:"websub_secret"`
+	WebSubState          string         `db:"websub_state"`
+	WebSubRequestedAt    sql.NullTime   `db:"websub_requested_at"`
+	WebSubVerifiedAt     sql.NullTime   `db:"websub_verified_at"`
+	WebSubLeaseExpiresAt sql.NullTime   `db:"websub_lease_expires_at"`
+	WebSubLastPushAt     sql.NullTime   `db:"websub_last_push_at"`
+	HTTPOptions          []byte         `db:"http_options"`
+	HTTPSecrets          string         `db:"http_secrets"`
+	CreatedAt            string         `db:"created_at"`
+}
+
+func (s dbSource) toModel(secrets *secret.Box) model.Source {
+	addedAt, err := time.Parse(time.RFC3339, s.CreatedAt)
+	if err != nil {
+		addedAt = time.Time{}
+	}
+
+	return model.Source{
+		ID:            s.ID,
+		Name:          s.Name,
+		FeedURL:       s.FeedURL,
+		Priority:      s.Priority,
+		Group:         s.GroupName,
+		Kind:          s.Kind,
+		Config:        s.Config,
+		ETag:          s.ETag.String,
+		LastModified:  s.LastModified.String,
+		FetchInterval: time.Duration(s.FetchIntervalSeconds.Int64) * time.Second,
+		FeedHints:     s.feedHints(),
+		NextFetchAt:   s.NextFetchAt.Time,
+		Health: model.SourceHealth{
+			LastSuccessAt:       s.LastSuccessAt.Time,
+			LastErrorAt:         s.LastErrorAt.Time,
+			LastError:           s.LastError.String,
+			ConsecutiveFailures: s.ConsecutiveFailures,
+			LastItemsCount:      s.LastItemsCount,
+			LastNewItemAt:       s.LastNewItemAt.Time,
+			ThrottleCount:       s.ThrottleCount,
+			LastThrottledAt:     s.LastThrottledAt.Time,
+			ThrottledUntil:      s.ThrottledUntil.Time,
+		},
+		Disabled:       s.Disabled,
+		DisabledReason: s.DisabledReason.String,
+		ChannelID:      s.TelegramChatID.Int64,
+		Filter:         s.FilterExpression,
+		WebSub: model.WebSub{
+			Hub:            s.WebSubHub,
+			Topic:          s.WebSubTopic,
+			Secret:         s.WebSubSecret,
+			State:          s.WebSubState,
+			RequestedAt:    s.WebSubRequestedAt.Time,
+			VerifiedAt:     s.WebSubVerifiedAt.Time,
+			LeaseExpiresAt: s.WebSubLeaseExpiresAt.Time,
+			LastPushAt:     s.WebSubLastPushAt.Time,
+		},
+		HTTP:      s.httpOptions(secrets),
+		CreatedAt: addedAt,
+	}
+}
+
+func (s dbSource) feedHints() model.FeedHints {
+	hints := model.FeedHints{Interval: time.Duration(s.FeedIntervalSeconds.Int64) * time.Second}
+	for _, hour := range s.FeedSkipHours {
+		hints.SkipHours = append(hints.SkipHours, int(hour))
+	}
+
+	return hints
 }
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"neuro_scout_bot_v1/internal/model"
 	"testing"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -20,7 +22,7 @@ func TestSourcePostgresStorage_Add(t *testing.T) {
 	defer mockDB.Close()
 
 	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-	storage := NewSourceStorage(sqlxDB)
+	storage := NewSourceStorage(sqlxDB, nil)
 
 	ctx := context.Background()
 	currentTime := time.Now().UTC()
@@ -34,7 +36,7 @@ func TestSourcePostgresStorage_Add(t *testing.T) {
 
 	// Setup the expected query and response
 	mock.ExpectQuery("INSERT INTO sources").
-		WithArgs(source.Name, source.FeedURL, source.Priority, source.CreatedAt).
+		WithArgs(source.Name, source.FeedURL, source.Priority, "", model.SourceKindRSS, []byte("{}"), source.CreatedAt).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
 	// Execute the method
@@ -53,7 +55,7 @@ func TestSourcePostgresStorage_Sources(t *testing.T) {
 	defer mockDB.Close()
 
 	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-	storage := NewSourceStorage(sqlxDB)
+	storage := NewSourceStorage(sqlxDB, nil)
 
 	ctx := context.Background()
 	timeStr := time.Now().UTC().Format(time.RFC3339)
@@ -83,7 +85,7 @@ func TestSourcePostgresStorage_SourceByID(t *testing.T) {
 	defer mockDB.Close()
 
 	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-	storage := NewSourceStorage(sqlxDB)
+	storage := NewSourceStorage(sqlxDB, nil)
 
 	ctx := context.Background()
 	timeStr := time.Now().UTC().Format(time.RFC3339)
@@ -115,7 +117,7 @@ func TestSourcePostgresStorage_Delete(t *testing.T) {
 	defer mockDB.Close()
 
 	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-	storage := NewSourceStorage(sqlxDB)
+	storage := NewSourceStorage(sqlxDB, nil)
 
 	ctx := context.Background()
 	sourceID := int64(1)
@@ -140,7 +142,7 @@ func TestSourcePostgresStorage_SetPriority(t *testing.T) {
 	defer mockDB.Close()
 
 	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-	storage := NewSourceStorage(sqlxDB)
+	storage := NewSourceStorage(sqlxDB, nil)
 
 	ctx := context.Background()
 	sourceID := int64(1)
@@ -159,6 +161,22 @@ func TestSourcePostgresStorage_SetPriority(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestSourcePostgresStorage_SetPriorityNotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	storage := NewSourceStorage(sqlx.NewDb(mockDB, "sqlmock"), nil)
+
+	mock.ExpectExec("UPDATE sources SET priority = \\$1 WHERE id = \\$2").
+		WithArgs(20, int64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = storage.SetPriority(context.Background(), 42, 20)
+	require.EqualError(t, err, "source 42 not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestSourcePostgresStorage_Error(t *testing.T) {
 	// Create a mock database
 	mockDB, mock, err := sqlmock.New()
@@ -166,7 +184,7 @@ func TestSourcePostgresStorage_Error(t *testing.T) {
 	defer mockDB.Close()
 
 	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-	storage := NewSourceStorage(sqlxDB)
+	storage := NewSourceStorage(sqlxDB, nil)
 
 	ctx := context.Background()
 	sourceID := int64(1)
@@ -184,3 +202,99 @@ func TestSourcePostgresStorage_Error(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to get source by id")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestSourcePostgresStorage_UpdateCacheValidators(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	storage := NewSourceStorage(sqlxDB, nil)
+
+	ctx := context.Background()
+	sourceID := int64(1)
+
+	mock.ExpectExec("UPDATE sources SET etag = \\$1, last_modified = \\$2, feed_interval_seconds = \\$3, feed_skip_hours = \\$4").
+		WithArgs(`"abc"`, "Wed, 21 Oct 2015 07:28:00 GMT", sql.NullInt64{Int64: 3600, Valid: true}, pq.Int64Array{0, 1}, sourceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = storage.UpdateCacheValidators(ctx, sourceID, `"abc"`, "Wed, 21 Oct 2015 07:28:00 GMT",
+		model.FeedHints{Interval: time.Hour, SkipHours: []int{0, 1}})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSourcePostgresStorage_RecordFetchFailure(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	storage := NewSourceStorage(sqlxDB, nil)
+
+	ctx := context.Background()
+	sourceID := int64(1)
+
+	mock.ExpectQuery("UPDATE sources SET (.+) RETURNING consecutive_failures").
+		WithArgs("connection refused", sourceID).
+		WillReturnRows(sqlmock.NewRows([]string{"consecutive_failures"}).AddRow(3))
+
+	failures, err := storage.RecordFetchFailure(ctx, sourceID, "connection refused")
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, failures)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSourcePostgresStorage_RecordThrottle(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	storage := NewSourceStorage(sqlx.NewDb(mockDB, "sqlmock"), nil)
+
+	until := time.Date(2025, 6, 17, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec("UPDATE sources SET throttle_count = throttle_count \\+ 1, (.+) throttled_until").
+		WithArgs(until, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, storage.RecordThrottle(context.Background(), 1, until))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSourcePostgresStorage_EnableStale(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	storage := NewSourceStorage(sqlx.NewDb(mockDB, "sqlmock"), nil)
+
+	// A source disabled for going stale must not be disabled again by its
+	// next fetch, so its stale period starts over.
+	mock.ExpectExec("UPDATE sources SET disabled = FALSE, (.+) last_new_item_at = NOW\\(\\) WHERE id = \\$1").
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, storage.Enable(context.Background(), 1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSourcePostgresStorage_SourceByChannelID_NotLinked(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	storage := NewSourceStorage(sqlx.NewDb(mockDB, "sqlmock"), nil)
+
+	mock.ExpectQuery("SELECT (.+) FROM sources WHERE telegram_chat_id").
+		WithArgs(int64(-1001234567890)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	source, err := storage.SourceByChannelID(context.Background(), -1001234567890)
+
+	require.NoError(t, err)
+	assert.Nil(t, source)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+// SetWebSubHub stores the hub and topic a feed advertises. A different hub
+// or topic drops the existing subscription, which has to be made again.
+func (s *SourcePostgresStorage) SetWebSubHub(ctx context.Context, sourceID int64, hub, topic string) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(
+		ctx,
+		`UPDATE sources SET
+			websub_hub = $1,
+			websub_topic = $2,
+			websub_secret = '',
+			websub_state = '',
+			websub_requested_at = NULL,
+			websub_verified_at = NULL,
+			websub_lease_expires_at = NULL
+		WHERE id = $3 AND (websub_hub <> $1 OR websub_topic <> $2)`,
+		hub, topic, sourceID,
+	); err != nil {
+		return fmt.Errorf("failed to update source websub hub: %w", err)
+	}
+
+	return nil
+}
+
+// RequestWebSub records that a subscription was requested from the hub with
+// the secret, and waits for the hub to verify it.
+func (s *SourcePostgresStorage) RequestWebSub(ctx context.Context, sourceID int64, secret string, at time.Time) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(
+		ctx,
+		`UPDATE sources SET
+			websub_secret = $1,
+			websub_state = CASE WHEN websub_state = $2 THEN websub_state ELSE $3 END,
+			websub_requested_at = $4
+		WHERE id = $5`,
+		secret, model.WebSubActive, model.WebSubPending, at.UTC(), sourceID,
+	); err != nil {
+		return fmt.Errorf("failed to record websub request: %w", err)
+	}
+
+	return nil
+}
+
+// ActivateWebSub records the hub's verification of the subscription.
+func (s *SourcePostgresStorage) ActivateWebSub(ctx context.Context, sourceID int64, leaseExpiresAt time.Time) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(
+		ctx,
+		`UPDATE sources SET
+			websub_state = $1,
+			websub_verified_at = NOW(),
+			websub_lease_expires_at = $2
+		WHERE id = $3`,
+		model.WebSubActive, leaseExpiresAt.UTC(), sourceID,
+	); err != nil {
+		return fmt.Errorf("failed to activate websub subscription: %w", err)
+	}
+
+	return nil
+}
+
+// SetWebSubState changes the state of the source's subscription.
+func (s *SourcePostgresStorage) SetWebSubState(ctx context.Context, sourceID int64, state string) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "UPDATE sources SET websub_state = $1 WHERE id = $2", state, sourceID); err != nil {
+		return fmt.Errorf("failed to update websub state: %w", err)
+	}
+
+	return nil
+}
+
+// RecordWebSubPush stores the time of the hub's latest content delivery.
+func (s *SourcePostgresStorage) RecordWebSubPush(ctx context.Context, sourceID int64, at time.Time) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "UPDATE sources SET websub_last_push_at = $1 WHERE id = $2", at.UTC(), sourceID); err != nil {
+		return fmt.Errorf("failed to record websub push: %w", err)
+	}
+
+	return nil
+}
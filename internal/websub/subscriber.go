@@ -0,0 +1,353 @@
+// Package websub subscribes to the WebSub hubs advertised by feeds and
+// receives the content they push.
+package websub
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"neuro_scout_bot_v1/internal/model"
+	sourcelib "neuro_scout_bot_v1/internal/source"
+)
+
+const (
+	// checkInterval is how often subscriptions are checked for renewal.
+	checkInterval = 5 * time.Minute
+	// retryAfter is the least time between two requests for the same
+	// subscription.
+	retryAfter = time.Hour
+	// deniedRetryAfter is how long a hub that denied a subscription is left
+	// alone.
+	deniedRetryAfter = 24 * time.Hour
+	// maxPushSize bounds the content a hub can deliver at once.
+	maxPushSize = 10 << 20
+	// ingestTimeout bounds the processing of one delivery.
+	ingestTimeout = 2 * time.Minute
+)
+
+type SourceStore interface {
+	Sources(ctx context.Context) ([]model.Source, error)
+	SourceByID(ctx context.Context, id int64) (*model.Source, error)
+	SetNextFetchAt(ctx context.Context, sourceID int64, at time.Time) error
+	RequestWebSub(ctx context.Context, sourceID int64, secret string, at time.Time) error
+	ActivateWebSub(ctx context.Context, sourceID int64, leaseExpiresAt time.Time) error
+	SetWebSubState(ctx context.Context, sourceID int64, state string) error
+	RecordWebSubPush(ctx context.Context, sourceID int64, at time.Time) error
+}
+
+// Ingester stores the items pushed by a hub the way fetched items are
+// stored.
+type Ingester interface {
+	Ingest(ctx context.Context, source model.Source, items []model.Item) (int, error)
+}
+
+// Subscriber keeps the WebSub subscriptions of feed sources and serves the
+// callback the hubs call.
+type Subscriber struct {
+	sources  SourceStore
+	ingester Ingester
+	client   *http.Client
+
+	callbackURL string
+	lease       time.Duration
+	quietAfter  time.Duration
+}
+
+// New returns a subscriber whose callback is served under callbackURL. A hub
+// that delivers nothing for quietAfter is subscribed again.
+func New(
+	sources SourceStore,
+	ingester Ingester,
+	callbackURL string,
+	lease time.Duration,
+	quietAfter time.Duration,
+) *Subscriber {
+	return &Subscriber{
+		sources:  sources,
+		ingester: ingester,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		callbackURL: strings.TrimRight(callbackURL, "/"),
+		lease:       lease,
+		quietAfter:  quietAfter,
+	}
+}
+
+// Start subscribes to the hubs of the sources that advertise one and renews
+// the subscriptions before their leases expire.
+func (s *Subscriber) Start(ctx context.Context) error {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	s.sync(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.sync(ctx)
+		}
+	}
+}
+
+func (s *Subscriber) sync(ctx context.Context) {
+	sources, err := s.sources.Sources(ctx)
+	if err != nil {
+		log.Printf("[ERROR] failed to get sources for WebSub: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, source := range sources {
+		if source.Disabled || source.IsPushed() || source.WebSub.Hub == "" {
+			continue
+		}
+
+		// The subscription carries the delivery secret, so hubs without
+		// https are not used.
+		if !secureHub(source.WebSub.Hub) {
+			continue
+		}
+
+		sub := source.WebSub
+
+		switch {
+		case sub.Active(now) && s.quietAfter > 0 && now.Sub(sub.LastHeardAt()) > s.quietAfter:
+			log.Printf("[WARN] WebSub hub %s went quiet for source %q, polling again", sub.Hub, source.Name)
+
+			if err := s.sources.SetWebSubState(ctx, source.ID, model.WebSubQuiet); err != nil {
+				log.Printf("[WARN] failed to mark WebSub subscription of source %q quiet: %v", source.Name, err)
+			}
+			if err := s.sources.SetNextFetchAt(ctx, source.ID, now); err != nil {
+				log.Printf("[WARN] failed to schedule fetch of source %q: %v", source.Name, err)
+			}
+			source.WebSub.State = model.WebSubQuiet
+		case sub.Active(now) && sub.LeaseExpiresAt.Sub(now) > s.lease/10:
+			continue
+		case sub.State == model.WebSubDenied && now.Sub(sub.RequestedAt) < deniedRetryAfter:
+			continue
+		case now.Sub(sub.RequestedAt) < retryAfter:
+			continue
+		}
+
+		if err := s.subscribe(ctx, source); err != nil {
+			log.Printf("[WARN] failed to subscribe to WebSub hub %s for source %q: %v", sub.Hub, source.Name, err)
+		}
+	}
+}
+
+// subscribe asks the hub for a subscription, or for its renewal. The secret
+// is kept across renewals.
+func (s *Subscriber) subscribe(ctx context.Context, source model.Source) error {
+	secret := source.WebSub.Secret
+	if secret == "" {
+		var err error
+		if secret, err = newSecret(); err != nil {
+			return err
+		}
+	}
+
+	// The hub can verify the subscription before it answers, so the secret
+	// is stored first.
+	if err := s.sources.RequestWebSub(ctx, source.ID, secret, time.Now()); err != nil {
+		return err
+	}
+
+	form := url.Values{
+		"hub.mode":          {"subscribe"},
+		"hub.topic":         {source.WebSub.Topic},
+		"hub.callback":      {s.callback(source.ID)},
+		"hub.secret":        {secret},
+		"hub.lease_seconds": {strconv.Itoa(int(s.lease.Seconds()))},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, source.WebSub.Hub, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected response status: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	log.Printf("[INFO] requested WebSub subscription to %s for source %q", source.WebSub.Topic, source.Name)
+
+	return nil
+}
+
+func secureHub(hub string) bool {
+	u, err := url.Parse(hub)
+	return err == nil && strings.EqualFold(u.Scheme, "https") && u.Host != ""
+}
+
+func (s *Subscriber) callback(sourceID int64) string {
+	return s.callbackURL + "/websub/" + strconv.FormatInt(sourceID, 10)
+}
+
+// Handler serves the callback: hubs verify subscriptions with GET requests
+// and deliver content with POST requests.
+func (s *Subscriber) Handler() http.Handler {
+	prefix := ""
+	if u, err := url.Parse(s.callbackURL); err == nil {
+		prefix = strings.TrimRight(u.Path, "/")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+prefix+"/websub/{id}", s.handleVerification)
+	mux.HandleFunc("POST "+prefix+"/websub/{id}", s.handleDelivery)
+
+	return mux
+}
+
+func (s *Subscriber) handleVerification(w http.ResponseWriter, r *http.Request) {
+	source, ok := s.source(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	topic := query.Get("hub.topic")
+
+	switch query.Get("hub.mode") {
+	case "subscribe":
+		lease, err := strconv.Atoi(query.Get("hub.lease_seconds"))
+		if source.Disabled || source.WebSub.Secret == "" || topic != source.WebSub.Topic || err != nil || lease <= 0 {
+			http.NotFound(w, r)
+			return
+		}
+
+		if err := s.sources.ActivateWebSub(r.Context(), source.ID, time.Now().Add(time.Duration(lease)*time.Second)); err != nil {
+			log.Printf("[ERROR] failed to activate WebSub subscription of source %q: %v", source.Name, err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("[INFO] WebSub subscription to %s for source %q verified for %ds", topic, source.Name, lease)
+	case "unsubscribe":
+		// Only subscriptions the source no longer wants are given up.
+		if !source.Disabled && topic == source.WebSub.Topic {
+			http.NotFound(w, r)
+			return
+		}
+	case "denied":
+		if topic == source.WebSub.Topic {
+			if err := s.sources.SetWebSubState(r.Context(), source.ID, model.WebSubDenied); err != nil {
+				log.Printf("[WARN] failed to mark WebSub subscription of source %q denied: %v", source.Name, err)
+			}
+		}
+
+		log.Printf("[WARN] WebSub hub denied subscription to %s for source %q: %s", topic, source.Name, query.Get("hub.reason"))
+		w.WriteHeader(http.StatusOK)
+		return
+	default:
+		http.Error(w, "unknown hub.mode", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = io.WriteString(w, query.Get("hub.challenge"))
+}
+
+func (s *Subscriber) handleDelivery(w http.ResponseWriter, r *http.Request) {
+	source, ok := s.source(w, r)
+	if !ok {
+		return
+	}
+
+	if source.Disabled || source.WebSub.Secret == "" {
+		http.Error(w, "not subscribed", http.StatusGone)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPushSize+1))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxPushSize {
+		http.Error(w, "content too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// Deliveries with a wrong signature are acknowledged and ignored, as
+	// the specification asks.
+	if !validSignature(r.Header.Get("X-Hub-Signature"), body, source.WebSub.Secret) {
+		log.Printf("[WARN] ignoring WebSub delivery for source %q with an invalid signature", source.Name)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+
+	// Processing resolves links over the network, which takes longer than
+	// hubs wait for an answer.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), ingestTimeout)
+	go func() {
+		defer cancel()
+		s.ingest(ctx, *source, body, r.Header.Get("Content-Type"))
+	}()
+}
+
+func (s *Subscriber) ingest(ctx context.Context, source model.Source, body []byte, contentType string) {
+	if err := s.sources.RecordWebSubPush(ctx, source.ID, time.Now()); err != nil {
+		log.Printf("[WARN] failed to record WebSub delivery for source %q: %v", source.Name, err)
+	}
+
+	items, _, err := sourcelib.ParseFeed(body, contentType, source.Name)
+	if err != nil {
+		log.Printf("[WARN] failed to parse WebSub delivery for source %q: %v", source.Name, err)
+		return
+	}
+
+	stored, err := s.ingester.Ingest(ctx, source, items)
+	if err != nil {
+		log.Printf("[ERROR] failed to ingest WebSub delivery for source %q: %v", source.Name, err)
+		return
+	}
+
+	log.Printf("[INFO] WebSub delivered %d items for source %q, %d stored", len(items), source.Name, stored)
+}
+
+func (s *Subscriber) source(w http.ResponseWriter, r *http.Request) (*model.Source, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return nil, false
+	}
+
+	source, err := s.sources.SourceByID(r.Context(), id)
+	if err != nil || source == nil {
+		http.NotFound(w, r)
+		return nil, false
+	}
+
+	return source, true
+}
+
+func newSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	return hex.EncodeToString(secret), nil
+}
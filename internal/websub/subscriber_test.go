@@ -0,0 +1,257 @@
+package websub
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"neuro_scout_bot_v1/internal/model"
+)
+
+type memorySources struct {
+	mu      sync.Mutex
+	sources map[int64]*model.Source
+}
+
+func (m *memorySources) get(id int64) model.Source {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return *m.sources[id]
+}
+
+func (m *memorySources) update(id int64, f func(*model.Source)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f(m.sources[id])
+}
+
+func (m *memorySources) Sources(context.Context) ([]model.Source, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sources []model.Source
+	for _, source := range m.sources {
+		sources = append(sources, *source)
+	}
+
+	return sources, nil
+}
+
+func (m *memorySources) SourceByID(_ context.Context, id int64) (*model.Source, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	source, ok := m.sources[id]
+	if !ok {
+		return nil, nil
+	}
+
+	copied := *source
+	return &copied, nil
+}
+
+func (m *memorySources) SetNextFetchAt(_ context.Context, id int64, at time.Time) error {
+	m.update(id, func(s *model.Source) { s.NextFetchAt = at })
+	return nil
+}
+
+func (m *memorySources) RequestWebSub(_ context.Context, id int64, secret string, at time.Time) error {
+	m.update(id, func(s *model.Source) {
+		s.WebSub.Secret = secret
+		s.WebSub.RequestedAt = at
+		if s.WebSub.State != model.WebSubActive {
+			s.WebSub.State = model.WebSubPending
+		}
+	})
+	return nil
+}
+
+func (m *memorySources) ActivateWebSub(_ context.Context, id int64, leaseExpiresAt time.Time) error {
+	m.update(id, func(s *model.Source) {
+		s.WebSub.State = model.WebSubActive
+		s.WebSub.VerifiedAt = time.Now()
+		s.WebSub.LeaseExpiresAt = leaseExpiresAt
+	})
+	return nil
+}
+
+func (m *memorySources) SetWebSubState(_ context.Context, id int64, state string) error {
+	m.update(id, func(s *model.Source) { s.WebSub.State = state })
+	return nil
+}
+
+func (m *memorySources) RecordWebSubPush(_ context.Context, id int64, at time.Time) error {
+	m.update(id, func(s *model.Source) { s.WebSub.LastPushAt = at })
+	return nil
+}
+
+type memoryIngester struct {
+	items chan []model.Item
+}
+
+func (m *memoryIngester) Ingest(_ context.Context, _ model.Source, items []model.Item) (int, error) {
+	m.items <- items
+	return len(items), nil
+}
+
+const pushedFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Blog</title>
+  <entry>
+    <title>Pushed post</title>
+    <link href="https://example.com/pushed"/>
+    <id>tag:example.com,2025:pushed</id>
+    <updated>2025-06-14T10:00:00Z</updated>
+  </entry>
+</feed>`
+
+func TestSubscriber(t *testing.T) {
+	// The hub stand-in records subscription requests; the test then plays
+	// the hub's part of verification and delivery.
+	requests := make(chan url.Values, 4)
+	hub := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		requests <- r.PostForm
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer hub.Close()
+
+	const topic = "https://example.com/feed.atom"
+
+	sources := &memorySources{sources: map[int64]*model.Source{
+		7: {ID: 7, Name: "Blog", FeedURL: topic, WebSub: model.WebSub{Hub: hub.URL, Topic: topic}},
+		8: {ID: 8, Name: "Plain", FeedURL: topic, WebSub: model.WebSub{Hub: "http://hub.example.com/", Topic: topic}},
+	}}
+	ingester := &memoryIngester{items: make(chan []model.Item, 1)}
+
+	var handler http.Handler
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	}))
+	defer callback.Close()
+
+	subscriber := New(sources, ingester, callback.URL+"/", 240*time.Hour, 24*time.Hour)
+	subscriber.client = hub.Client()
+	handler = subscriber.Handler()
+
+	ctx := context.Background()
+	subscriber.sync(ctx)
+
+	var request url.Values
+	select {
+	case request = <-requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no subscription request reached the hub")
+	}
+
+	assert.Equal(t, "subscribe", request.Get("hub.mode"))
+	assert.Equal(t, topic, request.Get("hub.topic"))
+	assert.Equal(t, callback.URL+"/websub/7", request.Get("hub.callback"))
+	assert.Equal(t, "864000", request.Get("hub.lease_seconds"))
+	secret := request.Get("hub.secret")
+	require.NotEmpty(t, secret)
+	assert.Equal(t, model.WebSubPending, sources.get(7).WebSub.State)
+	assert.Empty(t, sources.get(8).WebSub.State, "hubs without https get no secret and the source keeps polling")
+
+	subscriber.sync(ctx)
+	assert.Empty(t, requests, "pending subscriptions are not requested again right away")
+
+	verify := func(topic string) (int, string) {
+		query := url.Values{
+			"hub.mode":          {"subscribe"},
+			"hub.topic":         {topic},
+			"hub.challenge":     {"challenge-42"},
+			"hub.lease_seconds": {"3600"},
+		}
+		resp, err := http.Get(request.Get("hub.callback") + "?" + query.Encode())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	status, _ := verify("https://example.com/other")
+	assert.Equal(t, http.StatusNotFound, status, "verification of an unknown topic is refused")
+
+	status, body := verify(topic)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "challenge-42", body)
+	assert.True(t, sources.get(7).WebSub.Active(time.Now()))
+
+	deliver := func(signature string) int {
+		req, err := http.NewRequest(http.MethodPost, request.Get("hub.callback"), strings.NewReader(pushedFeed))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/atom+xml")
+		req.Header.Set("X-Hub-Signature", signature)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusAccepted, deliver("sha256=deadbeef"))
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(pushedFeed))
+	assert.Equal(t, http.StatusAccepted, deliver("sha256="+hex.EncodeToString(mac.Sum(nil))))
+
+	select {
+	case items := <-ingester.items:
+		require.Len(t, items, 1)
+		assert.Equal(t, "Pushed post", items[0].Title)
+		assert.Equal(t, "https://example.com/pushed", items[0].Link)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the signed delivery was not ingested")
+	}
+	assert.Empty(t, ingester.items, "the delivery with a bad signature is ignored")
+	assert.Eventually(t, func() bool { return !sources.get(7).WebSub.LastPushAt.IsZero() }, 5*time.Second, 10*time.Millisecond)
+
+	// A hub that delivers nothing for too long sends the source back to
+	// polling and is subscribed to again with the same secret.
+	sources.update(7, func(s *model.Source) {
+		s.WebSub.VerifiedAt = time.Now().Add(-48 * time.Hour)
+		s.WebSub.LastPushAt = time.Now().Add(-48 * time.Hour)
+		s.NextFetchAt = time.Now().Add(time.Hour)
+	})
+	subscriber.sync(ctx)
+
+	select {
+	case request = <-requests:
+		assert.Equal(t, secret, request.Get("hub.secret"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("the quiet subscription was not renewed")
+	}
+	assert.Equal(t, model.WebSubPending, sources.get(7).WebSub.State)
+	assert.False(t, sources.get(7).NextFetchAt.After(time.Now()))
+}
+
+func TestValidSignature(t *testing.T) {
+	body := []byte("payload")
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	assert.True(t, validSignature("sha256="+signature, body, "secret"))
+	assert.True(t, validSignature("SHA256="+strings.ToUpper(signature), body, "secret"))
+	assert.False(t, validSignature("sha256="+signature, body, "other"))
+	assert.False(t, validSignature("sha256="+signature, []byte("tampered"), "secret"))
+	assert.False(t, validSignature("md5="+signature, body, "secret"))
+	assert.False(t, validSignature("", body, "secret"))
+}